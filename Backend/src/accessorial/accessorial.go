@@ -0,0 +1,252 @@
+// Package accessorial maps the accessorial flags of a load's specifications
+// to Turvo stop services and to the billable line items charged for them.
+// Pickup accessorials are set on the first pickup and delivery accessorials on
+// the last delivery; rates in rateData.accessorials are billed to the
+// customer. The service and charge keys come from the tenant's lookup keys.
+package accessorial
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/model"
+)
+
+// Accessorial is an extra service a load can request.
+type Accessorial struct {
+	// Name is the CLSpecifications JSON field that requests it, and the key
+	// of its rate in CLRateData.Accessorials.
+	Name  string
+	Label string
+	// StopType is the kind of stop it is set on: the first pickup of the
+	// route for a pickup, the last delivery for a delivery.
+	StopType string
+
+	flag func(*model.CLSpecifications) *bool
+}
+
+// All lists every supported accessorial. Liftgate and inside service share a
+// Turvo code between pickup and delivery; the stop they are set on tells them
+// apart. Permits and escorts cover the whole trip and are set where it
+// starts; customs bonding and labor are set where the freight is delivered.
+var All = []Accessorial{
+	{
+		Name: "liftgatePickup", Label: "Liftgate - pickup", StopType: model.StopTypePickup,
+		flag: func(s *model.CLSpecifications) *bool { return &s.LiftgatePickup },
+	},
+	{
+		Name: "liftgateDelivery", Label: "Liftgate - delivery", StopType: model.StopTypeDelivery,
+		flag: func(s *model.CLSpecifications) *bool { return &s.LiftgateDelivery },
+	},
+	{
+		Name: "insidePickup", Label: "Inside pickup", StopType: model.StopTypePickup,
+		flag: func(s *model.CLSpecifications) *bool { return &s.InsidePickup },
+	},
+	{
+		Name: "insideDelivery", Label: "Inside delivery", StopType: model.StopTypeDelivery,
+		flag: func(s *model.CLSpecifications) *bool { return &s.InsideDelivery },
+	},
+	{
+		Name: "straps", Label: "Straps", StopType: model.StopTypePickup,
+		flag: func(s *model.CLSpecifications) *bool { return &s.Straps },
+	},
+	{
+		Name: "seal", Label: "Seal", StopType: model.StopTypePickup,
+		flag: func(s *model.CLSpecifications) *bool { return &s.Seal },
+	},
+	{
+		Name: "permits", Label: "Permits", StopType: model.StopTypePickup,
+		flag: func(s *model.CLSpecifications) *bool { return &s.Permits },
+	},
+	{
+		Name: "escorts", Label: "Escorts", StopType: model.StopTypePickup,
+		flag: func(s *model.CLSpecifications) *bool { return &s.Escorts },
+	},
+	{
+		Name: "customBonded", Label: "Customs bonded", StopType: model.StopTypeDelivery,
+		flag: func(s *model.CLSpecifications) *bool { return &s.CustomBonded },
+	},
+	{
+		Name: "labor", Label: "Labor", StopType: model.StopTypeDelivery,
+		flag: func(s *model.CLSpecifications) *bool { return &s.Labor },
+	},
+}
+
+// Requested reports whether spec asks for a.
+func (a Accessorial) Requested(spec model.CLSpecifications) bool {
+	return *a.flag(&spec)
+}
+
+// Service is the Turvo service code set on the stop a applies to.
+func (a Accessorial) Service() model.ValueKey {
+	return lookup.Current().Accessorials[a.Name].Service
+}
+
+// Charge is the Turvo line item code a is billed under.
+func (a Accessorial) Charge() model.ValueKey {
+	return lookup.Current().Accessorials[a.Name].Charge
+}
+
+// On reports whether a is set on stop i of route: the first pickup for a
+// pickup accessorial, the last delivery for a delivery one.
+func (a Accessorial) On(route []model.CLStop, i int) bool {
+	if a.StopType == model.StopTypePickup {
+		for j := range route {
+			if route[j].Type == model.StopTypePickup {
+				return i == j
+			}
+		}
+		return false
+	}
+	for j := len(route) - 1; j >= 0; j-- {
+		if route[j].Type == model.StopTypeDelivery {
+			return i == j
+		}
+	}
+	return false
+}
+
+// Lookup returns the accessorial named name.
+func Lookup(name string) (Accessorial, bool) {
+	for _, a := range All {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Accessorial{}, false
+}
+
+// IsService reports whether key is a Turvo service code managed here.
+func IsService(key string) bool {
+	for _, a := range All {
+		if a.Service().Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// StopServices returns the service codes spec requests on stop i of route.
+func StopServices(spec model.CLSpecifications, route []model.CLStop, i int) []model.ValueKey {
+	var out []model.ValueKey
+	seen := map[string]bool{}
+	for _, a := range All {
+		if service := a.Service(); a.Requested(spec) && a.On(route, i) && !seen[service.Key] {
+			seen[service.Key] = true
+			out = append(out, service)
+		}
+	}
+	return out
+}
+
+// MergeServices returns the services stop i of route should carry: the
+// current services not managed here, followed by those spec requests.
+func MergeServices(current []model.ValueKey, spec model.CLSpecifications, route []model.CLStop, i int) []model.ValueKey {
+	out := []model.ValueKey{}
+	for _, service := range current {
+		if !IsService(service.Key) {
+			out = append(out, model.ValueKey{Key: service.Key, Value: service.Value})
+		}
+	}
+	return append(out, StopServices(spec, route, i)...)
+}
+
+// SetFromServices sets the flags in spec requested by services found on a
+// stop of stopType. A service shared between a pickup and a delivery
+// accessorial is read by the stop's type; any other on whichever stop it is.
+func SetFromServices(spec *model.CLSpecifications, services []model.ValueKey, stopType string) {
+	for _, service := range services {
+		for _, a := range All {
+			if a.Service().Key == service.Key && (a.StopType == stopType || !a.sharesService()) {
+				*a.flag(spec) = true
+			}
+		}
+	}
+}
+
+// sharesService reports whether another accessorial is set as a's service.
+func (a Accessorial) sharesService() bool {
+	for _, other := range All {
+		if other.Name != a.Name && other.Service().Key == a.Service().Key {
+			return true
+		}
+	}
+	return false
+}
+
+// Charges returns one billable line item per accessorial rate. Every rate must
+// name a supported accessorial, and a non-zero rate one that spec requests; a
+// zero rate charges nothing.
+func Charges(spec model.CLSpecifications, rates map[string]float64) ([]model.LineItem, error) {
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var items []model.LineItem
+	var problems []string
+	for _, name := range names {
+		a, ok := Lookup(name)
+		rate := rates[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("unknown accessorial %q", name))
+		case rate < 0:
+			problems = append(problems, fmt.Sprintf("%s rate cannot be negative", name))
+		case rate == 0:
+			continue
+		case !a.Requested(spec):
+			problems = append(problems, fmt.Sprintf("%s is charged but not requested in specifications", name))
+		default:
+			items = append(items, model.LineItem{
+				Code:     a.Charge(),
+				Qty:      1,
+				Price:    rate,
+				Amount:   rate,
+				Billable: true,
+				Notes:    a.Label,
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid accessorial charges: %s", strings.Join(problems, "; "))
+	}
+	return items, nil
+}
+
+// ForCharge returns the accessorial item is billed for. Accessorials sharing a
+// charge code are told apart by the line item notes.
+func ForCharge(item model.LineItem) (Accessorial, bool) {
+	var match *Accessorial
+	for i := range All {
+		if All[i].Charge().Key != item.Code.Key {
+			continue
+		}
+		if match == nil || All[i].Label == item.Notes {
+			match = &All[i]
+		}
+	}
+	if match == nil {
+		return Accessorial{}, false
+	}
+	return *match, true
+}
+
+// RatesFromCharges reads accessorial rates back from line items.
+func RatesFromCharges(items []model.LineItem) map[string]float64 {
+	var rates map[string]float64
+	for _, item := range items {
+		a, ok := ForCharge(item)
+		if !ok {
+			continue
+		}
+		if rates == nil {
+			rates = map[string]float64{}
+		}
+		rates[a.Name] += item.Amount
+	}
+	return rates
+}
@@ -0,0 +1,104 @@
+package accessorial
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keys(services []model.ValueKey) []string {
+	var out []string
+	for _, s := range services {
+		out = append(out, s.Key)
+	}
+	return out
+}
+
+func route(types ...string) []model.CLStop {
+	stops := make([]model.CLStop, len(types))
+	for i, t := range types {
+		stops[i].Type = t
+	}
+	return stops
+}
+
+func TestStopServices(t *testing.T) {
+	spec := model.CLSpecifications{LiftgateDelivery: true, InsidePickup: true, Straps: true, Permits: true, Labor: true}
+	stops := route(model.StopTypePickup, model.StopTypeDelivery)
+
+	assert.Equal(t, []string{"3101", "3102", "3104"}, keys(StopServices(spec, stops, 0)))
+	assert.Equal(t, []string{"3100", "3107"}, keys(StopServices(spec, stops, 1)))
+	assert.Empty(t, StopServices(model.CLSpecifications{}, stops, 0))
+
+	both := model.CLSpecifications{LiftgatePickup: true, LiftgateDelivery: true}
+	assert.Equal(t, []string{"3100"}, keys(StopServices(both, stops, 0)))
+}
+
+func TestStopServices_OncePerRoute(t *testing.T) {
+	spec := model.CLSpecifications{LiftgatePickup: true, Permits: true, Escorts: true, CustomBonded: true, Labor: true}
+	stops := route(model.StopTypePickup, model.StopTypePickup, model.StopTypeDelivery, model.StopTypeDelivery)
+
+	assert.Equal(t, []string{"3100", "3104", "3105"}, keys(StopServices(spec, stops, 0)), "the first pickup")
+	assert.Empty(t, StopServices(spec, stops, 1))
+	assert.Empty(t, StopServices(spec, stops, 2))
+	assert.Equal(t, []string{"3106", "3107"}, keys(StopServices(spec, stops, 3)), "the last delivery")
+}
+
+func TestStopServices_TenantKeys(t *testing.T) {
+	keys := lookup.Default()
+	keys.Accessorials["straps"] = lookup.Accessorial{Service: model.ValueKey{Key: "S9"}, Charge: model.ValueKey{Key: "C9"}}
+	lookup.Use(keys)
+	t.Cleanup(func() { lookup.Use(lookup.Default()) })
+
+	spec := model.CLSpecifications{Straps: true}
+	assert.Equal(t, []model.ValueKey{{Key: "S9"}}, StopServices(spec, route(model.StopTypePickup, model.StopTypeDelivery), 0))
+	items, err := Charges(spec, map[string]float64{"straps": 40})
+	require.NoError(t, err)
+	assert.Equal(t, "C9", items[0].Code.Key)
+	assert.Equal(t, map[string]float64{"straps": 40}, RatesFromCharges(items))
+}
+
+func TestMergeServices(t *testing.T) {
+	current := []model.ValueKey{{Key: "9000", Value: "Appointment required"}, {Key: "3100", Value: "Liftgate"}}
+
+	merged := MergeServices(current, model.CLSpecifications{Labor: true}, route(model.StopTypePickup, model.StopTypeDelivery), 1)
+	assert.Equal(t, []string{"9000", "3107"}, keys(merged))
+}
+
+func TestSetFromServices(t *testing.T) {
+	var spec model.CLSpecifications
+	SetFromServices(&spec, []model.ValueKey{{Key: "3100"}, {Key: "3104"}}, model.StopTypeDelivery)
+	SetFromServices(&spec, []model.ValueKey{{Key: "3101"}, {Key: "3102"}}, model.StopTypePickup)
+
+	assert.Equal(t, model.CLSpecifications{LiftgateDelivery: true, Permits: true, InsidePickup: true, Straps: true}, spec)
+}
+
+func TestCharges(t *testing.T) {
+	spec := model.CLSpecifications{LiftgatePickup: true, LiftgateDelivery: true, Escorts: true}
+
+	items, err := Charges(spec, map[string]float64{"liftgatePickup": 75, "liftgateDelivery": 90, "escorts": 0})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, model.LineItem{Code: model.ValueKey{Key: "1617", Value: "Liftgate"}, Qty: 1, Price: 90, Amount: 90, Billable: true, Notes: "Liftgate - delivery"}, items[0])
+	assert.Equal(t, "Liftgate - pickup", items[1].Notes)
+
+	assert.Equal(t, map[string]float64{"liftgatePickup": 75, "liftgateDelivery": 90}, RatesFromCharges(append(items,
+		model.LineItem{Code: model.ValueKey{Key: "1600"}, Amount: 2000})))
+}
+
+func TestCharges_Rejects(t *testing.T) {
+	spec := model.CLSpecifications{Labor: true}
+	for name, rates := range map[string]map[string]float64{
+		"unknown":       {"detention": 50},
+		"not requested": {"seal": 10},
+		"negative":      {"labor": -5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Charges(spec, rates)
+			assert.ErrorContains(t, err, "invalid accessorial charges")
+		})
+	}
+}
@@ -1,15 +1,35 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
+	"drumkit.com/interview/src/equipment"
 	"drumkit.com/interview/src/gateway"
 	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/rating"
 	"drumkit.com/interview/src/service"
 	"github.com/aws/aws-lambda-go/lambda"
 )
 
 func main() {
-	gw := gateway.NewTurvoAPIGateway()
+	gw, err := gateway.NewTMSForTenant(os.Getenv("TENANT_ID"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to configure TMS: %v", err))
+	}
+	keys, err := lookup.ForTenant(os.Getenv("TENANT_ID"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to load Turvo lookup keys: %v", err))
+	}
+	lookup.Use(keys)
 	svc := service.NewLoadService(gw)
+	if svc.Equipment, err = equipment.ForTenant(os.Getenv("TENANT_ID")); err != nil {
+		panic(fmt.Sprintf("failed to load equipment rules: %v", err))
+	}
+	if svc.Margin, err = rating.MarginPolicyForTenant(os.Getenv("TENANT_ID")); err != nil {
+		panic(fmt.Sprintf("failed to configure margin floor: %v", err))
+	}
 	h := &handler.CreateLoadsHandler{Service: svc}
 	lambda.Start(h.CreateLoadsHandlerLambda)
 }
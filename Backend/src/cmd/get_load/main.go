@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/service"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	gw, err := gateway.NewTMSForTenant(os.Getenv("TENANT_ID"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to configure TMS: %v", err))
+	}
+	keys, err := lookup.ForTenant(os.Getenv("TENANT_ID"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to load Turvo lookup keys: %v", err))
+	}
+	lookup.Use(keys)
+	svc := service.NewLoadService(gw)
+	h := &handler.GetLoadHandler{Service: svc}
+	lambda.Start(h.GetLoadHandlerLambda)
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"drumkit.com/interview/src/gateway"
 	"drumkit.com/interview/src/handler"
 	"drumkit.com/interview/src/service"
@@ -8,7 +11,10 @@ import (
 )
 
 func main() {
-	gw := gateway.NewTurvoAPIGateway()
+	gw, err := gateway.NewTMSForTenant(os.Getenv("TENANT_ID"))
+	if err != nil {
+		panic(fmt.Sprintf("failed to configure TMS: %v", err))
+	}
 	svc := service.NewLoadService(gw)
 	h := &handler.ViewLoadsHandler{Service: svc}
 	lambda.Start(h.ViewLoadsHandlerLambda) // Corrected function name to match the handler's method
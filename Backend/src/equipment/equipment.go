@@ -0,0 +1,209 @@
+// Package equipment maps a load's specifications to the Turvo equipment type
+// and size it ships on, using ordered rules loaded from JSON:
+//
+//	{"rules": [
+//	  {"name": "step deck", "when": {"oversized": true, "maxWeight": 48000},
+//	   "type": {"key": "...", "value": "Step deck"}, "size": {"key": "...", "value": "48ft"}},
+//	  {"name": "van", "type": {"key": "1200", "value": "Van"}, "size": {"key": "1000", "value": "53ft"}}
+//	]}
+//
+// The first rule whose conditions all hold wins, so the last rule must have
+// none and catch every load. Rules with "reefer" set also send the load's
+// temperature.
+package equipment
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"drumkit.com/interview/src/model"
+)
+
+//go:embed default.json
+var defaultRules []byte
+
+var (
+	defaultOnce   sync.Once
+	defaultMapper *Mapper
+)
+
+// Condition restricts a rule to some loads. Nil fields are not checked.
+type Condition struct {
+	TemperatureControlled *bool `json:"temperatureControlled,omitempty"`
+	Tarps                 *bool `json:"tarps,omitempty"`
+	Oversized             *bool `json:"oversized,omitempty"`
+	Hazmat                *bool `json:"hazmat,omitempty"`
+	Straps                *bool `json:"straps,omitempty"`
+	Permits               *bool `json:"permits,omitempty"`
+	Escorts               *bool `json:"escorts,omitempty"`
+	PowerOnly             *bool `json:"powerOnly,omitempty"`
+	// Liftgate holds when either the pickup or the delivery needs one.
+	Liftgate *bool `json:"liftgate,omitempty"`
+	// Weights are in pounds and inclusive.
+	MinWeight  *float64 `json:"minWeight,omitempty"`
+	MaxWeight  *float64 `json:"maxWeight,omitempty"`
+	MinPallets *int     `json:"minPallets,omitempty"`
+	MaxPallets *int     `json:"maxPallets,omitempty"`
+}
+
+// Rule maps the loads matching When to a Turvo equipment type and size.
+type Rule struct {
+	Name string         `json:"name"`
+	When Condition      `json:"when"`
+	Type model.ValueKey `json:"type"`
+	Size model.ValueKey `json:"size"`
+	// Reefer sends the load's temperature with the equipment.
+	Reefer bool `json:"reefer,omitempty"`
+}
+
+// Mapper picks the equipment for a load from an ordered rule list.
+type Mapper struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Load reads and validates a rule file.
+func Load(r io.Reader) (*Mapper, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var m Mapper
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("equipment rules: %w", err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("equipment rules: %w", err)
+	}
+	return &m, nil
+}
+
+// LoadFile reads and validates the rule file at path.
+func LoadFile(path string) (*Mapper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("equipment rules: %w", err)
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Default returns the built-in rules: reefer for temperature-controlled
+// loads, flatbed for tarped or oversized loads, otherwise a 53ft dry van.
+// Other equipment, such as the step deck, conestoga, box truck and power only
+// rules in example_rules.json, comes from a tenant rule file. The rules are
+// parsed once; each call returns its own copy.
+func Default() *Mapper {
+	defaultOnce.Do(func() {
+		m, err := Load(bytes.NewReader(defaultRules))
+		if err != nil {
+			panic(err)
+		}
+		defaultMapper = m
+	})
+	return &Mapper{Rules: append([]Rule(nil), defaultMapper.Rules...)}
+}
+
+// ForTenant loads the rules configured for tenant. It reads the file named by
+// EQUIPMENT_RULES_<TENANT> first, then EQUIPMENT_RULES, and falls back to
+// Default.
+func ForTenant(tenant string) (*Mapper, error) {
+	if tenant != "" {
+		key := "EQUIPMENT_RULES_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(tenant))
+		if path := os.Getenv(key); path != "" {
+			return LoadFile(path)
+		}
+	}
+	if path := os.Getenv("EQUIPMENT_RULES"); path != "" {
+		return LoadFile(path)
+	}
+	return Default(), nil
+}
+
+func (m *Mapper) validate() error {
+	if len(m.Rules) == 0 {
+		return errors.New("no rules")
+	}
+	for i, rule := range m.Rules {
+		if rule.Type.Key == "" || rule.Size.Key == "" {
+			return fmt.Errorf("rule %d (%s): type and size keys are required", i+1, rule.Name)
+		}
+	}
+	if last := m.Rules[len(m.Rules)-1]; last.When != (Condition{}) {
+		return fmt.Errorf("last rule (%s) must have no conditions so every load matches", last.Name)
+	}
+	return nil
+}
+
+// Rule returns the first rule matching load.
+func (m *Mapper) Rule(load model.CreateLoadRequest) Rule {
+	for _, rule := range m.Rules {
+		if rule.When.Matches(load) {
+			return rule
+		}
+	}
+	// validate guarantees a catch-all last rule
+	return m.Rules[len(m.Rules)-1]
+}
+
+// Map returns the equipment entry for load.
+func (m *Mapper) Map(load model.CreateLoadRequest) model.Equipment {
+	rule := m.Rule(load)
+	equipment := model.Equipment{
+		Operation:   model.OperationInsert,
+		Type:        rule.Type,
+		Size:        rule.Size,
+		Weight:      int(load.TotalWeight),
+		WeightUnits: model.ValueKey{Key: "1520", Value: "lb"},
+	}
+	if rule.Reefer {
+		// prefer minTemp if present, otherwise maxTemp
+		equipment.Temp = load.Specifications.MinTempFahrenheit
+		if equipment.Temp == 0 {
+			equipment.Temp = load.Specifications.MaxTempFahrenheit
+		}
+		equipment.TempUnits = model.ValueKey{Key: "1510", Value: "°F"}
+	}
+	return equipment
+}
+
+// Matches reports whether every condition set on c holds for load.
+func (c Condition) Matches(load model.CreateLoadRequest) bool {
+	spec := load.Specifications
+	flags := []struct {
+		want *bool
+		got  bool
+	}{
+		{c.TemperatureControlled, spec.MinTempFahrenheit != 0 || spec.MaxTempFahrenheit != 0},
+		{c.Tarps, spec.Tarps},
+		{c.Oversized, spec.Oversized},
+		{c.Hazmat, spec.Hazmat},
+		{c.Straps, spec.Straps},
+		{c.Permits, spec.Permits},
+		{c.Escorts, spec.Escorts},
+		{c.PowerOnly, spec.PowerOnly},
+		{c.Liftgate, spec.LiftgatePickup || spec.LiftgateDelivery},
+	}
+	for _, f := range flags {
+		if f.want != nil && *f.want != f.got {
+			return false
+		}
+	}
+	if c.MinWeight != nil && load.TotalWeight < *c.MinWeight {
+		return false
+	}
+	if c.MaxWeight != nil && load.TotalWeight > *c.MaxWeight {
+		return false
+	}
+	if c.MinPallets != nil && load.InPalletCount < *c.MinPallets {
+		return false
+	}
+	if c.MaxPallets != nil && load.InPalletCount > *c.MaxPallets {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package equipment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tenantRules = `{"rules": [
+	{"name": "power only", "when": {"maxPallets": 0, "maxWeight": 0},
+	 "type": {"key": "9001", "value": "Power only"}, "size": {"key": "9100", "value": "N/A"}},
+	{"name": "conestoga", "when": {"oversized": true, "tarps": true},
+	 "type": {"key": "9002", "value": "Conestoga"}, "size": {"key": "1000", "value": "53ft"}},
+	{"name": "step deck", "when": {"oversized": true, "minWeight": 30000},
+	 "type": {"key": "9003", "value": "Step deck"}, "size": {"key": "9101", "value": "48ft"}},
+	{"name": "box truck", "when": {"maxPallets": 12, "liftgate": true},
+	 "type": {"key": "9004", "value": "Box truck"}, "size": {"key": "9102", "value": "26ft"}},
+	{"name": "reefer", "when": {"temperatureControlled": true},
+	 "type": {"key": "1208", "value": "Refrigerated"}, "size": {"key": "1000", "value": "53ft"}, "reefer": true},
+	{"name": "van", "type": {"key": "1200", "value": "Van"}, "size": {"key": "1000", "value": "53ft"}}
+]}`
+
+func TestDefault(t *testing.T) {
+	m := Default()
+	for name, tc := range map[string]struct {
+		load     model.CreateLoadRequest
+		wantType string
+		wantTemp int
+	}{
+		"van":       {model.CreateLoadRequest{TotalWeight: 20000}, "Van", 0},
+		"reefer":    {model.CreateLoadRequest{Specifications: model.CLSpecifications{MinTempFahrenheit: 34, MaxTempFahrenheit: 38}}, "Refrigerated", 34},
+		"max only":  {model.CreateLoadRequest{Specifications: model.CLSpecifications{MaxTempFahrenheit: 38}}, "Refrigerated", 38},
+		"tarps":     {model.CreateLoadRequest{Specifications: model.CLSpecifications{Tarps: true}}, "Flatbed", 0},
+		"oversized": {model.CreateLoadRequest{Specifications: model.CLSpecifications{Oversized: true, Permits: true}}, "Flatbed", 0},
+		"liftgate":  {model.CreateLoadRequest{TotalWeight: 6000, InPalletCount: 8, Specifications: model.CLSpecifications{LiftgateDelivery: true}}, "Van", 0},
+	} {
+		got := m.Map(tc.load)
+		assert.Equal(t, tc.wantType, got.Type.Value, name)
+		assert.Equal(t, "53ft", got.Size.Value, name)
+		assert.Equal(t, tc.wantTemp, got.Temp, name)
+		assert.Equal(t, int(tc.load.TotalWeight), got.Weight, name)
+	}
+}
+
+func TestDefault_ReturnsACopy(t *testing.T) {
+	m := Default()
+	m.Rules[0].Type.Key = "changed"
+	m.Rules = m.Rules[:1]
+
+	assert.Equal(t, "1208", Default().Rules[0].Type.Key)
+	assert.Len(t, Default().Rules, 4)
+}
+
+func TestLoadFile_ExampleRules(t *testing.T) {
+	m, err := LoadFile("example_rules.json")
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		load     model.CreateLoadRequest
+		wantType string
+		wantSize string
+	}{
+		"van":                {model.CreateLoadRequest{TotalWeight: 20000}, "Van", "53ft"},
+		"power only":         {model.CreateLoadRequest{TotalWeight: 40000, Specifications: model.CLSpecifications{PowerOnly: true, Tarps: true}}, "Power only", "53ft"},
+		"conestoga":          {model.CreateLoadRequest{Specifications: model.CLSpecifications{Oversized: true, Tarps: true, Permits: true}}, "Conestoga", "53ft"},
+		"step deck":          {model.CreateLoadRequest{TotalWeight: 38000, Specifications: model.CLSpecifications{Oversized: true, Permits: true}}, "Step deck", "48ft"},
+		"flatbed":            {model.CreateLoadRequest{Specifications: model.CLSpecifications{Tarps: true}}, "Flatbed", "53ft"},
+		"box truck":          {model.CreateLoadRequest{TotalWeight: 6000, InPalletCount: 8, Specifications: model.CLSpecifications{LiftgateDelivery: true}}, "Box truck", "26ft"},
+		"heavy liftgate":     {model.CreateLoadRequest{TotalWeight: 18000, InPalletCount: 8, Specifications: model.CLSpecifications{LiftgatePickup: true}}, "Van", "53ft"},
+		"unknown pallets":    {model.CreateLoadRequest{Specifications: model.CLSpecifications{LiftgateDelivery: true}}, "Van", "53ft"},
+		"reefer beats power": {model.CreateLoadRequest{Specifications: model.CLSpecifications{PowerOnly: true, MinTempFahrenheit: 34}}, "Refrigerated", "53ft"},
+	} {
+		got := m.Map(tc.load)
+		assert.Equal(t, tc.wantType, got.Type.Value, name)
+		assert.Equal(t, tc.wantSize, got.Size.Value, name)
+	}
+}
+
+func TestLoad_TenantRules(t *testing.T) {
+	m, err := Load(strings.NewReader(tenantRules))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		load model.CreateLoadRequest
+		want string
+	}{
+		"power only":                    {model.CreateLoadRequest{}, "Power only"},
+		"conestoga":                     {model.CreateLoadRequest{TotalWeight: 40000, Specifications: model.CLSpecifications{Oversized: true, Tarps: true}}, "Conestoga"},
+		"step deck":                     {model.CreateLoadRequest{TotalWeight: 40000, Specifications: model.CLSpecifications{Oversized: true}}, "Step deck"},
+		"light oversized falls through": {model.CreateLoadRequest{TotalWeight: 10000, InPalletCount: 20, Specifications: model.CLSpecifications{Oversized: true}}, "Van"},
+		"box truck":                     {model.CreateLoadRequest{TotalWeight: 6000, InPalletCount: 8, Specifications: model.CLSpecifications{LiftgateDelivery: true}}, "Box truck"},
+		"reefer":                        {model.CreateLoadRequest{TotalWeight: 6000, InPalletCount: 20, Specifications: model.CLSpecifications{MinTempFahrenheit: 34}}, "Refrigerated"},
+	} {
+		assert.Equal(t, tc.want, m.Map(tc.load).Type.Value, name)
+	}
+	assert.Equal(t, "26ft", m.Map(model.CreateLoadRequest{TotalWeight: 1, InPalletCount: 8, Specifications: model.CLSpecifications{LiftgatePickup: true}}).Size.Value)
+}
+
+func TestLoad_RejectsInvalidRules(t *testing.T) {
+	for name, body := range map[string]string{
+		"no rules":       `{"rules": []}`,
+		"missing keys":   `{"rules": [{"name": "van", "type": {"value": "Van"}, "size": {"key": "1000"}}]}`,
+		"no catch-all":   `{"rules": [{"name": "reefer", "when": {"temperatureControlled": true}, "type": {"key": "1208"}, "size": {"key": "1000"}}]}`,
+		"unknown field":  `{"rules": [{"name": "van", "when": {"color": "red"}, "type": {"key": "1200"}, "size": {"key": "1000"}}]}`,
+		"malformed JSON": `{"rules": [`,
+	} {
+		_, err := Load(strings.NewReader(body))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestForTenant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acme.json")
+	require.NoError(t, os.WriteFile(path, []byte(tenantRules), 0o600))
+
+	t.Setenv("EQUIPMENT_RULES_ACME_CO", path)
+	m, err := ForTenant("acme-co")
+	require.NoError(t, err)
+	assert.Len(t, m.Rules, 6)
+
+	m, err = ForTenant("other")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), m)
+
+	t.Setenv("EQUIPMENT_RULES", filepath.Join(t.TempDir(), "missing.json"))
+	_, err = ForTenant("other")
+	assert.Error(t, err)
+}
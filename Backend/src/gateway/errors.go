@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrorKind classifies a TMS failure independently of the provider's status codes.
+type ErrorKind string
+
+const (
+	KindValidation  ErrorKind = "validation"
+	KindNotFound    ErrorKind = "not_found"
+	KindAuth        ErrorKind = "auth"
+	KindRateLimited ErrorKind = "rate_limited"
+	KindUnavailable ErrorKind = "upstream_unavailable"
+	KindConflict    ErrorKind = "conflict"
+	KindUpstream    ErrorKind = "upstream_error"
+)
+
+// Sentinel errors matched by errors.Is against a *TurvoError of the same kind.
+var (
+	ErrValidation  = errors.New("validation failed")
+	ErrNotFound    = errors.New("not found")
+	ErrAuth        = errors.New("authentication with TMS failed")
+	ErrRateLimited = errors.New("rate limited by TMS")
+	ErrUnavailable = errors.New("TMS unavailable")
+	ErrConflict    = errors.New("conflict")
+	ErrUpstream    = errors.New("unexpected TMS error")
+)
+
+var sentinels = map[ErrorKind]error{
+	KindValidation:  ErrValidation,
+	KindNotFound:    ErrNotFound,
+	KindAuth:        ErrAuth,
+	KindRateLimited: ErrRateLimited,
+	KindUnavailable: ErrUnavailable,
+	KindConflict:    ErrConflict,
+	KindUpstream:    ErrUpstream,
+}
+
+// ErrorPayload is the error body Turvo returns, either the API envelope
+// ({"Status":"ERROR","details":{...}}) or the OAuth form ({"error": ...}).
+type ErrorPayload struct {
+	Status  string `json:"Status,omitempty"`
+	Details struct {
+		ErrorCode    string `json:"errorCode,omitempty"`
+		ErrorMessage string `json:"errorMessage,omitempty"`
+	} `json:"details"`
+	OAuthError       string `json:"error,omitempty"`
+	OAuthDescription string `json:"error_description,omitempty"`
+}
+
+// Message returns the most descriptive message in the payload.
+func (p *ErrorPayload) Message() string {
+	if p == nil {
+		return ""
+	}
+	switch {
+	case p.Details.ErrorMessage != "":
+		return p.Details.ErrorMessage
+	case p.OAuthDescription != "":
+		return p.OAuthDescription
+	default:
+		return p.OAuthError
+	}
+}
+
+// TurvoError is a failed TMS operation. StatusCode is 0 when the request never
+// got a response or was rejected before it was sent.
+type TurvoError struct {
+	Kind       ErrorKind
+	Op         string
+	StatusCode int
+	Payload    *ErrorPayload
+	// Body is the raw response body, kept when it could not be parsed.
+	Body string
+	Err  error
+}
+
+func (e *TurvoError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, ": status code: %d", e.StatusCode)
+	}
+	if msg := e.Payload.Message(); msg != "" {
+		fmt.Fprintf(&b, ": %s", msg)
+	} else if e.Body != "" {
+		fmt.Fprintf(&b, ", response: %s", e.Body)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	return b.String()
+}
+
+func (e *TurvoError) Unwrap() error { return e.Err }
+
+// Is lets errors.Is(err, ErrNotFound) and friends match on Kind.
+func (e *TurvoError) Is(target error) bool {
+	return sentinels[e.Kind] == target
+}
+
+// KindForStatus maps a Turvo HTTP status to an ErrorKind.
+func KindForStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return KindValidation
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return KindAuth
+	case status == http.StatusNotFound:
+		return KindNotFound
+	case status == http.StatusConflict:
+		return KindConflict
+	case status == http.StatusTooManyRequests:
+		return KindRateLimited
+	case status == 0 || status >= 500:
+		return KindUnavailable
+	default:
+		return KindUpstream
+	}
+}
+
+// newValidationError reports a request that was rejected before reaching Turvo.
+func newValidationError(op string, err error) error {
+	return &TurvoError{Kind: KindValidation, Op: op, Err: err}
+}
+
+// responseError builds the error for an unexpected Turvo response and consumes its body.
+func responseError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	return statusError(op, resp.StatusCode, string(body), nil)
+}
+
+// requestError converts an error returned by execute into a *TurvoError,
+// keeping the retry history and any context cancellation reachable via Unwrap.
+func requestError(op string, err error) error {
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		return statusError(op, retryErr.StatusCode(), retryErr.Body, err)
+	}
+	var turvoErr *TurvoError
+	if errors.As(err, &turvoErr) {
+		return err
+	}
+	return &TurvoError{Kind: KindUnavailable, Op: op, Err: err}
+}
+
+func statusError(op string, status int, body string, err error) *TurvoError {
+	turvoErr := &TurvoError{Kind: KindForStatus(status), Op: op, StatusCode: status, Err: err}
+	var payload ErrorPayload
+	if body != "" && json.Unmarshal([]byte(body), &payload) == nil && (payload.Message() != "" || payload.Status != "") {
+		turvoErr.Payload = &payload
+	} else {
+		turvoErr.Body = body
+	}
+	return turvoErr
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/customer"
+	"drumkit.com/interview/src/test/faketurvo"
+	"github.com/stretchr/testify/suite"
+)
+
+type TurvoErrorsTestSuite struct {
+	suite.Suite
+	turvo *faketurvo.Server
+	gw    *TurvoAPIGateway
+}
+
+func (suite *TurvoErrorsTestSuite) SetupTest() {
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.gw = NewTurvoAPIGateway()
+	suite.Require().NotNil(suite.gw)
+	suite.gw.sleep = func(time.Duration) {}
+}
+
+func (suite *TurvoErrorsTestSuite) TestStatusesMapToKinds() {
+	cases := []struct {
+		status int
+		target error
+	}{
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusForbidden, ErrAuth},
+		{http.StatusConflict, ErrConflict},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusServiceUnavailable, ErrUnavailable},
+		{http.StatusTeapot, ErrUpstream},
+	}
+	for _, tc := range cases {
+		suite.turvo.ClearFailures()
+		suite.turvo.Inject(faketurvo.Failure{
+			Path:   "/customers/list",
+			Status: tc.status,
+			Body:   `{"Status":"ERROR","details":{"errorCode":"E1","errorMessage":"boom"}}`,
+		})
+
+		_, err := suite.gw.RetrieveCustomers(context.Background(), customer.Query{Name: "Bunker"})
+		suite.ErrorIs(err, tc.target, "status %d", tc.status)
+
+		var turvoErr *TurvoError
+		suite.Require().True(errors.As(err, &turvoErr))
+		suite.Equal(tc.status, turvoErr.StatusCode)
+		suite.Require().NotNil(turvoErr.Payload)
+		suite.Equal("E1", turvoErr.Payload.Details.ErrorCode)
+		suite.Equal("boom", turvoErr.Payload.Message())
+	}
+}
+
+func (suite *TurvoErrorsTestSuite) TestUnparsableBodyIsKept() {
+	suite.turvo.Inject(faketurvo.Failure{Path: "/locations/list", Status: http.StatusNotFound, Body: "<html>nope</html>"})
+
+	_, err := suite.gw.RetrieveLocations(context.Background(), "test")
+	var turvoErr *TurvoError
+	suite.Require().True(errors.As(err, &turvoErr))
+	suite.Nil(turvoErr.Payload)
+	suite.Equal("<html>nope</html>", turvoErr.Body)
+}
+
+func (suite *TurvoErrorsTestSuite) TestBadCredentialsAreAuthErrors() {
+	suite.gw.Password = "wrong"
+	suite.turvo.RevokeTokens()
+
+	_, err := suite.gw.RetrieveLoads(context.Background(), model.LoadQuery{})
+	suite.ErrorIs(err, ErrAuth)
+}
+
+func TestTurvoErrorsTestSuite(t *testing.T) {
+	suite.Run(t, new(TurvoErrorsTestSuite))
+}
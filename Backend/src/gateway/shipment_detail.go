@@ -0,0 +1,221 @@
+package gateway
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"drumkit.com/interview/src/accessorial"
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/rating"
+)
+
+const (
+	stopTypePickup   = "1500"
+	stopTypeDelivery = "1501"
+	externalIDTypePO = "1400"
+)
+
+// LoadFromShipment maps a Turvo shipment back into the Drumkit load format.
+// Every pickup and delivery on the route becomes a stop; the first pickup and
+// the last delivery also fill the pickup and consignee. The first live
+// customer and carrier orders supply the parties and rates.
+func LoadFromShipment(s model.ShipmentDetail) model.LoadDetail {
+	load := model.LoadDetail{
+		ID:       s.ID,
+		CustomID: s.CustomID,
+		CreateLoadRequest: model.CreateLoadRequest{
+			ExternalTMSLoadID: strconv.Itoa(s.ID),
+			FreightLoadID:     s.CustomID,
+			Status:            s.Status.Code.Value,
+		},
+	}
+
+	stops := routeStops(s.GlobalRoute)
+	for _, stop := range stops {
+		cl := clStop(stop)
+		load.Stops = append(load.Stops, cl)
+		accessorial.SetFromServices(&load.Specifications, stop.Services, cl.Type)
+	}
+	if i := firstStop(stops, stopTypePickup); i >= 0 {
+		p := load.Stops[i]
+		load.Pickup = model.CLPickup{
+			ExternalTMSId: p.ExternalTMSId,
+			Name:          p.Name,
+			AddressLine1:  p.AddressLine1,
+			AddressLine2:  p.AddressLine2,
+			City:          p.City,
+			State:         p.State,
+			Zipcode:       p.Zipcode,
+			Country:       p.Country,
+			ReadyTime:     p.ReadyTime,
+			ApptTime:      p.ApptTime,
+			ApptNote:      p.ApptNote,
+			Timezone:      p.Timezone,
+		}
+	}
+	if i := lastStop(stops, stopTypeDelivery); i >= 0 {
+		c := load.Stops[i]
+		load.Consignee = model.CLConsignee{
+			ExternalTMSId: c.ExternalTMSId,
+			Name:          c.Name,
+			AddressLine1:  c.AddressLine1,
+			AddressLine2:  c.AddressLine2,
+			City:          c.City,
+			State:         c.State,
+			Zipcode:       c.Zipcode,
+			Country:       c.Country,
+			MustDeliver:   c.MustDeliver,
+			ApptTime:      c.ApptTime,
+			ApptNote:      c.ApptNote,
+			Timezone:      c.Timezone,
+		}
+	}
+
+	var customerLinehaul, carrierLinehaul float64
+	if order, ok := liveCustomerOrder(s.CustomerOrder); ok {
+		load.Customer = model.CLCustomer{
+			ExternalTMSId: idString(order.Customer.ID),
+			Name:          order.Customer.Name,
+		}
+		rate := rating.FromCosts(order.Costs)
+		load.RateData.CustomerRateType = rate.Type
+		load.RateData.CustomerLhRateUsd = rate.LhRateUsd
+		load.RateData.CustomerNumHours = rate.NumHours
+		load.RateData.FscPercent = rate.FscPercent
+		load.RateData.FscPerMile = rate.FscPerMile
+		load.RouteMiles = rate.RouteMiles
+		customerLinehaul = rate.Linehaul
+		load.RateData.Accessorials = accessorial.RatesFromCharges(order.Costs.LineItem)
+		for _, item := range order.Items {
+			load.InPalletCount += item.HandlingQty
+			load.Specifications.Hazmat = load.Specifications.Hazmat || item.IsHazmat
+		}
+		load.OutPalletCount = load.InPalletCount
+		load.NumCommodities = len(order.Items)
+
+		var poNumbers []string
+		for _, ext := range order.ExternalIds {
+			if ext.Type.Key == externalIDTypePO {
+				poNumbers = append(poNumbers, ext.Value)
+			}
+		}
+		load.PoNums = strings.Join(poNumbers, ",")
+	}
+
+	if order, ok := liveCarrierOrder(s.CarrierOrder); ok {
+		load.Carrier = model.CLCarrier{
+			ExternalTMSId: idString(order.Carrier.ID),
+			Name:          order.Carrier.Name,
+		}
+		if len(order.Drivers) > 0 {
+			load.Carrier.FirstDriverName = order.Drivers[0].Name
+			load.Carrier.FirstDriverPhone = order.Drivers[0].Phone
+		}
+		if len(order.Drivers) > 1 {
+			load.Carrier.SecondDriverName = order.Drivers[1].Name
+			load.Carrier.SecondDriverPhone = order.Drivers[1].Phone
+		}
+		rate := rating.FromCosts(order.Costs)
+		load.RateData.CarrierRateType = rate.Type
+		load.RateData.CarrierLhRateUsd = rate.LhRateUsd
+		load.RateData.CarrierNumHours = rate.NumHours
+		carrierLinehaul = rate.Linehaul
+	}
+	if customerLinehaul != 0 && carrierLinehaul != 0 {
+		profit := customerLinehaul - carrierLinehaul
+		load.RateData.NetProfitUsd = profit
+		load.RateData.ProfitPercent = profit / customerLinehaul * 100
+	}
+
+	if len(s.Equipment) > 0 {
+		equipment := s.Equipment[0]
+		load.TotalWeight = float64(equipment.Weight)
+		load.BillableWeight = load.TotalWeight
+		if equipment.TempUnits.Key != "" {
+			load.Specifications.MinTempFahrenheit = equipment.Temp
+			load.Specifications.MaxTempFahrenheit = equipment.Temp
+		}
+	}
+	// items carry the temperature range; equipment only its set point
+	if order, ok := liveCustomerOrder(s.CustomerOrder); ok && len(order.Items) > 0 {
+		if lo, hi := order.Items[0].MinTemp.Temp, order.Items[0].MaxTemp.Temp; lo != 0 || hi != 0 {
+			load.Specifications.MinTempFahrenheit = lo
+			load.Specifications.MaxTempFahrenheit = hi
+		}
+	}
+	return load
+}
+
+// routeStops returns the pickup and delivery stops of route in sequence order.
+func routeStops(route []model.RouteStop) []model.RouteStop {
+	var stops []model.RouteStop
+	for _, stop := range route {
+		if stop.StopType.Key == stopTypePickup || stop.StopType.Key == stopTypeDelivery {
+			stops = append(stops, stop)
+		}
+	}
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].Sequence < stops[j].Sequence })
+	return stops
+}
+
+func clStop(stop model.RouteStop) model.CLStop {
+	stopType := model.StopTypePickup
+	if stop.StopType.Key == stopTypeDelivery {
+		stopType = model.StopTypeDelivery
+	}
+	cl := model.CLStop{
+		Type:          stopType,
+		ExternalTMSId: idString(stop.Location.ID),
+		Name:          stop.Name,
+		AddressLine1:  stop.Address.Line1,
+		AddressLine2:  stop.Address.Line2,
+		City:          stop.Address.City,
+		State:         stop.Address.State,
+		Zipcode:       stop.Address.Zip,
+		Country:       stop.Address.Country,
+		ApptTime:      stop.Appointment.Date,
+		ApptNote:      stop.Notes,
+		Timezone:      stop.Timezone,
+	}
+	// a first come, first served window runs from the ready time to the
+	// appointment at a pickup, and from the appointment to the must-deliver
+	// time at a delivery
+	if planned := stop.PlannedAppointmentDate; planned.SchedulingType.Key == lookup.Current().Scheduling.FCFS.Key {
+		window := planned.Appointment
+		if stopType == model.StopTypePickup {
+			cl.ReadyTime, cl.ApptTime = window.From.Date, window.To.Date
+		} else {
+			cl.ApptTime, cl.MustDeliver = window.From.Date, window.To.Date
+		}
+	}
+	return cl
+}
+
+// firstStop returns the index of the first stop of stopType, or -1.
+func firstStop(stops []model.RouteStop, stopType string) int {
+	for i, stop := range stops {
+		if stop.StopType.Key == stopType {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastStop returns the index of the last stop of stopType, or -1.
+func lastStop(stops []model.RouteStop, stopType string) int {
+	for i := len(stops) - 1; i >= 0; i-- {
+		if stops[i].StopType.Key == stopType {
+			return i
+		}
+	}
+	return -1
+}
+
+func idString(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFromShipment(t *testing.T) {
+	shipment := model.ShipmentDetail{
+		ID:       1042,
+		CustomID: "M-1042",
+		Status:   model.AvroStatus{Code: model.ValueKey{Key: "2102", Value: "Covered"}},
+		Equipment: []model.Equipment{{
+			Weight:    42000,
+			Temp:      34,
+			TempUnits: model.ValueKey{Key: "1510", Value: "°F"},
+		}},
+		GlobalRoute: []model.RouteStop{
+			{
+				GlobalRoute: model.GlobalRoute{
+					Name:        "Shipper",
+					StopType:    model.ValueKey{Key: "1500", Value: "Pickup"},
+					Location:    model.Location{ID: 11},
+					Timezone:    "America/Chicago",
+					Appointment: model.Appointment{Date: "2025-08-01T13:00:00Z"},
+					Notes:       "dock 4",
+				},
+				Address: model.StopAddress{Line1: "1 Main St", City: "Chicago", State: "IL", Zip: "60601"},
+			},
+			{GlobalRoute: model.GlobalRoute{Name: "Cross dock", StopType: model.ValueKey{Key: "1501"}, Location: model.Location{ID: 12}}},
+			{
+				GlobalRoute: model.GlobalRoute{
+					Name:        "Receiver",
+					StopType:    model.ValueKey{Key: "1501", Value: "Delivery"},
+					Location:    model.Location{ID: 13},
+					Appointment: model.Appointment{Date: "2025-08-03T15:00:00Z"},
+				},
+				Address: model.StopAddress{City: "Los Angeles", State: "CA"},
+			},
+		},
+		CustomerOrder: []model.CustomerOrderDetail{
+			{ID: 1, Deleted: true, CustomerOrderAvro: model.CustomerOrderAvro{Customer: model.CustomerAvro{ID: 1, Name: "Old"}}},
+			{ID: 2, CustomerOrderAvro: model.CustomerOrderAvro{
+				Customer: model.CustomerAvro{ID: 973069, Name: "Bunker"},
+				Items: []model.Item{
+					{HandlingQty: 10},
+					{HandlingQty: 4, IsHazmat: true},
+				},
+				Costs: model.Costs{TotalAmount: 2000},
+				ExternalIds: []model.ExternalID{
+					{Type: model.ValueKey{Key: "1400"}, Value: "PO-1"},
+					{Type: model.ValueKey{Key: "1401"}, Value: "BOL-9"},
+					{Type: model.ValueKey{Key: "1400"}, Value: "PO-2"},
+				},
+			}},
+		},
+		CarrierOrder: []model.CarrierOrderDetail{
+			{
+				CarrierOrderAvro: model.CarrierOrderAvro{
+					Carrier: model.CarrierAvro{ID: 834187, Name: "Fast Freight"},
+					Costs:   model.Costs{TotalAmount: 1500},
+				},
+			},
+		},
+	}
+
+	load := LoadFromShipment(shipment)
+
+	assert.Equal(t, 1042, load.ID)
+	assert.Equal(t, "1042", load.ExternalTMSLoadID)
+	assert.Equal(t, "M-1042", load.FreightLoadID)
+	assert.Equal(t, "Covered", load.Status)
+	assert.Equal(t, model.CLCustomer{ExternalTMSId: "973069", Name: "Bunker"}, load.Customer)
+	assert.Equal(t, model.CLPickup{
+		ExternalTMSId: "11",
+		Name:          "Shipper",
+		AddressLine1:  "1 Main St",
+		City:          "Chicago",
+		State:         "IL",
+		Zipcode:       "60601",
+		ApptTime:      "2025-08-01T13:00:00Z",
+		ApptNote:      "dock 4",
+		Timezone:      "America/Chicago",
+	}, load.Pickup)
+	assert.Equal(t, "Receiver", load.Consignee.Name)
+	assert.Equal(t, "13", load.Consignee.ExternalTMSId)
+	assert.Equal(t, "Fast Freight", load.Carrier.Name)
+	assert.Equal(t, "834187", load.Carrier.ExternalTMSId)
+	assert.Equal(t, 14, load.InPalletCount)
+	assert.Equal(t, 2, load.NumCommodities)
+	assert.True(t, load.Specifications.Hazmat)
+	assert.Equal(t, 34, load.Specifications.MinTempFahrenheit)
+	assert.Equal(t, 42000.0, load.TotalWeight)
+	assert.Equal(t, "PO-1,PO-2", load.PoNums)
+	assert.Equal(t, 2000.0, load.RateData.CustomerLhRateUsd)
+	assert.Equal(t, 1500.0, load.RateData.CarrierLhRateUsd)
+	assert.Equal(t, 500.0, load.RateData.NetProfitUsd)
+	assert.Equal(t, 25.0, load.RateData.ProfitPercent)
+}
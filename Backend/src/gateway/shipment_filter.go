@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"drumkit.com/interview/src/model"
+)
+
+// turvoTimeFormat is how timestamps are written in Turvo filter values.
+const turvoTimeFormat = "2006-01-02T15:04:05Z"
+
+// encodeLoadQuery renders query as a /shipments/list query string. Filters use
+// Turvo's field[op]=value syntax; the brackets are kept literal, as Turvo
+// expects, and only the values are escaped.
+func encodeLoadQuery(query model.LoadQuery) string {
+	params := map[string]string{}
+	if query.Start > 0 {
+		params["start"] = strconv.Itoa(query.Start)
+	}
+	if query.PageSize > 0 {
+		params["pageSize"] = strconv.Itoa(query.PageSize)
+	}
+	if query.LastObjectKey != "" {
+		params["lastObjectKey"] = query.LastObjectKey
+	}
+
+	f := query.Filter
+	if len(f.Statuses) > 0 {
+		codes := make([]string, len(f.Statuses))
+		for i, code := range f.Statuses {
+			codes[i] = string(code)
+		}
+		params["status[in]"] = strings.Join(codes, ",")
+	}
+	if f.CustomerID != 0 {
+		params["customerId[eq]"] = strconv.Itoa(f.CustomerID)
+	}
+	if f.CarrierID != 0 {
+		params["carrierId[eq]"] = strconv.Itoa(f.CarrierID)
+	}
+	setTime(params, "pickupDate[gte]", f.PickupFrom)
+	setTime(params, "pickupDate[lte]", f.PickupTo)
+	setTime(params, "deliveryDate[gte]", f.DeliveryFrom)
+	setTime(params, "deliveryDate[lte]", f.DeliveryTo)
+	setTime(params, "updated[gte]", f.UpdatedSince)
+	if f.Origin != "" {
+		params["lane.start[eq]"] = f.Origin
+	}
+	if f.Destination != "" {
+		params["lane.end[eq]"] = f.Destination
+	}
+
+	if query.Sort.Field != "" {
+		params["sortBy"] = string(query.Sort.Field)
+		params["sortDirection"] = "asc"
+		if query.Sort.Descending {
+			params["sortDirection"] = "desc"
+		}
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		if strings.HasSuffix(k, "[in]") {
+			// list values look like [a,b]; status codes need no escaping
+			parts[i] = k + "=[" + params[k] + "]"
+			continue
+		}
+		parts[i] = k + "=" + url.QueryEscape(params[k])
+	}
+	return strings.Join(parts, "&")
+}
+
+func setTime(params map[string]string, key string, t time.Time) {
+	if !t.IsZero() {
+		params[key] = t.UTC().Format(turvoTimeFormat)
+	}
+}
@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeLoadQuery(t *testing.T) {
+	raw := encodeLoadQuery(model.LoadQuery{
+		Start:    10,
+		PageSize: 5,
+		Filter: model.ShipmentFilter{
+			Statuses:     []model.ShipmentStatusCode{model.StatusTendered, model.StatusCovered},
+			CustomerID:   973069,
+			PickupFrom:   time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+			DeliveryTo:   time.Date(2025, 8, 2, 6, 0, 0, 0, time.FixedZone("CDT", -5*3600)),
+			Origin:       "Chicago, IL",
+			UpdatedSince: time.Date(2025, 8, 19, 0, 0, 0, 0, time.UTC),
+		},
+		Sort: model.ShipmentSort{Field: model.SortByUpdated, Descending: true},
+	})
+
+	assert.Equal(t, "customerId[eq]=973069"+
+		"&deliveryDate[lte]=2025-08-02T11%3A00%3A00Z"+
+		"&lane.start[eq]=Chicago%2C+IL"+
+		"&pageSize=5"+
+		"&pickupDate[gte]=2025-08-01T00%3A00%3A00Z"+
+		"&sortBy=updated&sortDirection=desc"+
+		"&start=10"+
+		"&status[in]=[2101,2102]"+
+		"&updated[gte]=2025-08-19T00%3A00%3A00Z", raw)
+}
+
+func TestEncodeLoadQuery_Empty(t *testing.T) {
+	assert.Equal(t, "", encodeLoadQuery(model.LoadQuery{}))
+}
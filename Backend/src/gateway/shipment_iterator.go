@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"context"
+
+	"drumkit.com/interview/src/model"
+)
+
+// ShipmentIterator walks the shipment list page by page:
+//
+//	it := NewShipmentIterator(tms, model.LoadQuery{PageSize: 100}, 50)
+//	for it.Next(ctx) {
+//		process(it.Page())
+//	}
+//	if err := it.Err(); err != nil { ... }
+type ShipmentIterator struct {
+	tms      TMS
+	query    model.LoadQuery
+	maxPages int
+
+	page       []model.Shipment
+	pagination model.Pagination
+	fetched    int
+	done       bool
+	truncated  bool
+	err        error
+}
+
+// NewShipmentIterator starts at query and stops after maxPages pages.
+// A maxPages of zero or less means no limit.
+func NewShipmentIterator(tms TMS, query model.LoadQuery, maxPages int) *ShipmentIterator {
+	return &ShipmentIterator{tms: tms, query: query, maxPages: maxPages}
+}
+
+// Next fetches the next page. It returns false when there are no more pages,
+// the page limit was reached or a request failed.
+func (it *ShipmentIterator) Next(ctx context.Context) bool {
+	if it.done {
+		return false
+	}
+	if it.maxPages > 0 && it.fetched >= it.maxPages {
+		it.done = true
+		it.truncated = it.pagination.MoreAvailable
+		return false
+	}
+
+	details, err := it.tms.RetrieveLoads(ctx, it.query)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+	it.fetched++
+	it.page = details.Shipments
+	it.pagination = details.Pagination
+
+	if len(details.Shipments) == 0 {
+		it.done = true
+		return false
+	}
+	if !details.Pagination.MoreAvailable {
+		it.done = true
+	}
+	it.query = NextLoadQuery(it.query, details)
+	return true
+}
+
+// Page returns the shipments fetched by the last successful call to Next.
+func (it *ShipmentIterator) Page() []model.Shipment { return it.page }
+
+// Pagination returns Turvo's pagination metadata for the current page.
+func (it *ShipmentIterator) Pagination() model.Pagination { return it.pagination }
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ShipmentIterator) Err() error { return it.err }
+
+// Truncated reports whether iteration stopped at the page limit while Turvo
+// still had more shipments.
+func (it *ShipmentIterator) Truncated() bool { return it.truncated }
+
+// CollectShipments gathers every shipment reachable from query, up to maxPages pages.
+func CollectShipments(ctx context.Context, tms TMS, query model.LoadQuery, maxPages int) ([]model.Shipment, bool, error) {
+	var all []model.Shipment
+	it := NewShipmentIterator(tms, query, maxPages)
+	for it.Next(ctx) {
+		all = append(all, it.Page()...)
+	}
+	return all, it.Truncated(), it.Err()
+}
+
+// NextLoadQuery returns the query for the page following details.
+func NextLoadQuery(query model.LoadQuery, details model.ShipmentsDetails) model.LoadQuery {
+	next := query
+	next.Start = query.Start + len(details.Shipments)
+	if details.Pagination.LastObjectKey != nil {
+		next.LastObjectKey = *details.Pagination.LastObjectKey
+	}
+	return next
+}
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/test/faketurvo"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShipmentIteratorTestSuite struct {
+	suite.Suite
+	turvo *faketurvo.Server
+	gw    *TurvoAPIGateway
+}
+
+func (suite *ShipmentIteratorTestSuite) SetupTest() {
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	for i := 0; i < 25; i++ {
+		suite.turvo.AddShipment(model.Shipment{CustomID: fmt.Sprintf("SEED-%d", i)})
+	}
+	suite.gw = NewTurvoAPIGateway()
+	suite.Require().NotNil(suite.gw)
+	suite.gw.sleep = func(time.Duration) {}
+}
+
+func (suite *ShipmentIteratorTestSuite) TestWalksAllPages() {
+	all, truncated, err := CollectShipments(context.Background(), suite.gw, model.LoadQuery{PageSize: 10}, 0)
+	suite.Require().NoError(err)
+	suite.False(truncated)
+	suite.Len(all, 25)
+	suite.Len(suite.turvo.RequestsTo("GET", "/shipments/list"), 3)
+
+	ids := map[int]bool{}
+	for _, s := range all {
+		ids[s.ID] = true
+	}
+	suite.Len(ids, 25, "pages must not overlap")
+}
+
+func (suite *ShipmentIteratorTestSuite) TestStopsAtMaxPages() {
+	all, truncated, err := CollectShipments(context.Background(), suite.gw, model.LoadQuery{PageSize: 10}, 2)
+	suite.Require().NoError(err)
+	suite.True(truncated)
+	suite.Len(all, 20)
+}
+
+func (suite *ShipmentIteratorTestSuite) TestSurfacesErrors() {
+	suite.gw.Retry = RetryPolicy{MaxAttempts: 1}
+	it := NewShipmentIterator(suite.gw, model.LoadQuery{PageSize: 10}, 0)
+	suite.Require().True(it.Next(context.Background()))
+	suite.Len(it.Page(), 10)
+
+	suite.turvo.Inject(faketurvo.Failure{Path: "/shipments/list", Status: http.StatusBadGateway})
+	suite.False(it.Next(context.Background()))
+	suite.ErrorIs(it.Err(), ErrUnavailable)
+}
+
+func TestShipmentIteratorTestSuite(t *testing.T) {
+	suite.Run(t, new(ShipmentIteratorTestSuite))
+}
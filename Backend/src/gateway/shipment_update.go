@@ -0,0 +1,565 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"drumkit.com/interview/src/accessorial"
+	"drumkit.com/interview/src/equipment"
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/rating"
+	"drumkit.com/interview/src/schedule"
+)
+
+const ltlWeightLimit = 15000
+
+// ShipmentUpdateFor diffs desired against the load current maps to and builds
+// the Turvo update that applies the difference. Stop appointments and notes,
+// rates, equipment, item handling and PO numbers can change; changing the
+// load's identity, status, parties or stop locations is a validation error.
+// Fields the Turvo mapping does not carry are ignored. Equipment is chosen by
+// rules, or by equipment.Default when rules is nil.
+func ShipmentUpdateFor(current model.ShipmentDetail, desired model.CreateLoadRequest, rules *equipment.Mapper) (model.ShipmentUpdate, error) {
+	const op = "update shipment"
+	base := LoadFromShipment(current).CreateLoadRequest
+
+	if fields := readOnlyChanges(base, desired); len(fields) > 0 {
+		return model.ShipmentUpdate{}, newValidationError(op, fmt.Errorf("cannot change %s", strings.Join(fields, ", ")))
+	}
+
+	var update model.ShipmentUpdate
+
+	stops := routeStops(current.GlobalRoute)
+	for i, to := range RouteFor(base, desired) {
+		if stopFieldsOf(to) == stopFieldsOf(base.Stops[i]) {
+			continue
+		}
+		stop, err := stopChange(stops[i], base.Stops[i], to)
+		if err != nil {
+			return model.ShipmentUpdate{}, newValidationError(op, fmt.Errorf("stop %d: %w", i+1, err))
+		}
+		update.GlobalRoute = append(update.GlobalRoute, stop.stop)
+		if i == 0 {
+			update.StartDate = shipmentDate(stop.at)
+		}
+		if i == len(stops)-1 {
+			update.EndDate = shipmentDate(stop.at)
+		}
+	}
+
+	if base.Specifications != desired.Specifications {
+		for i, stop := range stops {
+			services := accessorial.MergeServices(stop.Services, desired.Specifications, base.Stops, i)
+			if sameServices(stop.Services, services) {
+				continue
+			}
+			update.GlobalRoute = withStopUpdate(update.GlobalRoute, stop.ID, func(u *model.StopUpdate) {
+				u.Services = &services
+			})
+		}
+	}
+
+	if base.TotalWeight != desired.TotalWeight || base.Specifications != desired.Specifications {
+		if rules == nil {
+			rules = equipment.Default()
+		}
+		if change, ok := equipmentChange(current.Equipment, rules.Map(desired)); ok {
+			update.Equipment = []model.Equipment{change}
+		}
+		if ltl := desired.TotalWeight < ltlWeightLimit; ltl != current.LTLShipment {
+			update.LTLShipment = &ltl
+		}
+	}
+
+	customerOrder, err := customerOrderChange(current.CustomerOrder, base, desired)
+	if err != nil {
+		return model.ShipmentUpdate{}, newValidationError(op, err)
+	}
+	if customerOrder != nil {
+		update.CustomerOrder = []model.CustomerOrderUpdate{*customerOrder}
+	}
+
+	if carrierRateChanged(base, desired) {
+		order, ok := liveCarrierOrder(current.CarrierOrder)
+		if !ok {
+			return model.ShipmentUpdate{}, newValidationError(op, errors.New("load has no carrier order to rate"))
+		}
+		lines, err := rating.CarrierLineItems(desired.RateData, desired.RouteMiles)
+		if err != nil {
+			return model.ShipmentUpdate{}, newValidationError(op, err)
+		}
+		changes, delta := lineItemChanges(order.Costs.LineItem, lines, linehaulKey)
+		costs := model.Costs{TotalAmount: order.Costs.TotalAmount + delta, LineItem: changes}
+		if len(order.Costs.LineItem) == 0 {
+			// the whole total was the linehaul
+			costs.TotalAmount = rating.Total(lines)
+		}
+		update.CarrierOrder = []model.CarrierOrderUpdate{{ID: order.ID, Operation: model.OperationUpdate, Costs: &costs}}
+	}
+
+	return update, nil
+}
+
+// withStopUpdate applies change to the update of stop id in updates, adding
+// one when the stop has none yet.
+func withStopUpdate(updates []model.StopUpdate, id int, change func(*model.StopUpdate)) []model.StopUpdate {
+	for i := range updates {
+		if updates[i].ID == id {
+			change(&updates[i])
+			return updates
+		}
+	}
+	u := model.StopUpdate{ID: id, Operation: model.OperationUpdate}
+	change(&u)
+	return append(updates, u)
+}
+
+// sameServices compares service lists by key, ignoring order.
+func sameServices(a, b []model.ValueKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := map[string]int{}
+	for _, s := range a {
+		count[s.Key]++
+	}
+	for _, s := range b {
+		if count[s.Key]--; count[s.Key] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// readOnlyChanges lists the fields that differ between base and desired but
+// cannot be changed by an update.
+func readOnlyChanges(base, desired model.CreateLoadRequest) []string {
+	var fields []string
+	check := func(name, from, to string) {
+		if from != to {
+			fields = append(fields, name)
+		}
+	}
+	check("externalTMSLoadID", base.ExternalTMSLoadID, desired.ExternalTMSLoadID)
+	check("freightLoadID", base.FreightLoadID, desired.FreightLoadID)
+	check("status", base.Status, desired.Status)
+	check("customer.externalTMSId", base.Customer.ExternalTMSId, desired.Customer.ExternalTMSId)
+	check("customer.name", base.Customer.Name, desired.Customer.Name)
+	check("carrier.externalTMSId", base.Carrier.ExternalTMSId, desired.Carrier.ExternalTMSId)
+	check("carrier.name", base.Carrier.Name, desired.Carrier.Name)
+
+	check("pickup.externalTMSId", base.Pickup.ExternalTMSId, desired.Pickup.ExternalTMSId)
+	check("pickup.name", base.Pickup.Name, desired.Pickup.Name)
+	check("pickup.addressLine1", base.Pickup.AddressLine1, desired.Pickup.AddressLine1)
+	check("pickup.addressLine2", base.Pickup.AddressLine2, desired.Pickup.AddressLine2)
+	check("pickup.city", base.Pickup.City, desired.Pickup.City)
+	check("pickup.state", base.Pickup.State, desired.Pickup.State)
+	check("pickup.zipcode", base.Pickup.Zipcode, desired.Pickup.Zipcode)
+	check("pickup.country", base.Pickup.Country, desired.Pickup.Country)
+
+	check("consignee.externalTMSId", base.Consignee.ExternalTMSId, desired.Consignee.ExternalTMSId)
+	check("consignee.name", base.Consignee.Name, desired.Consignee.Name)
+	check("consignee.addressLine1", base.Consignee.AddressLine1, desired.Consignee.AddressLine1)
+	check("consignee.addressLine2", base.Consignee.AddressLine2, desired.Consignee.AddressLine2)
+	check("consignee.city", base.Consignee.City, desired.Consignee.City)
+	check("consignee.state", base.Consignee.State, desired.Consignee.State)
+	check("consignee.zipcode", base.Consignee.Zipcode, desired.Consignee.Zipcode)
+	check("consignee.country", base.Consignee.Country, desired.Consignee.Country)
+
+	if len(base.Stops) != len(desired.Stops) {
+		return append(fields, "the number of stops")
+	}
+	for i := range base.Stops {
+		from, to := base.Stops[i], desired.Stops[i]
+		prefix := fmt.Sprintf("stops[%d].", i)
+		check(prefix+"type", from.Type, to.Type)
+		check(prefix+"externalTMSId", from.ExternalTMSId, to.ExternalTMSId)
+		check(prefix+"name", from.Name, to.Name)
+		check(prefix+"addressLine1", from.AddressLine1, to.AddressLine1)
+		check(prefix+"addressLine2", from.AddressLine2, to.AddressLine2)
+		check(prefix+"city", from.City, to.City)
+		check(prefix+"state", from.State, to.State)
+		check(prefix+"zipcode", from.Zipcode, to.Zipcode)
+		check(prefix+"country", from.Country, to.Country)
+	}
+	return fields
+}
+
+// stopFields are the parts of a stop an update can change.
+type stopFields struct {
+	ApptTime    string
+	ReadyTime   string
+	MustDeliver string
+	Timezone    string
+	Note        string
+}
+
+func stopFieldsOf(stop model.CLStop) stopFields {
+	return stopFields{stop.ApptTime, stop.ReadyTime, stop.MustDeliver, stop.Timezone, stop.ApptNote}
+}
+
+// retimed reports whether the stop's times change between f and to.
+func (f stopFields) retimed(to stopFields) bool {
+	return f.ApptTime != to.ApptTime || f.ReadyTime != to.ReadyTime ||
+		f.MustDeliver != to.MustDeliver || f.Timezone != to.Timezone
+}
+
+// RouteFor returns the stops of desired, a patched copy of base, with the
+// changes made through the pickup and consignee objects applied to the first
+// pickup and last delivery. Those win over changes made to the same stop
+// through stops. Stops are returned unchanged when their number differs from
+// base's, which an update refuses anyway.
+func RouteFor(base, desired model.CreateLoadRequest) []model.CLStop {
+	if len(desired.Stops) != len(base.Stops) {
+		return desired.Stops
+	}
+	route := append([]model.CLStop(nil), desired.Stops...)
+	if pickup := desired.Pickup.Stop(); stopFieldsOf(pickup) != stopFieldsOf(base.Pickup.Stop()) {
+		if i := firstOfType(route, model.StopTypePickup); i >= 0 {
+			stopFieldsOf(pickup).apply(&route[i])
+		}
+	}
+	if consignee := desired.Consignee.Stop(); stopFieldsOf(consignee) != stopFieldsOf(base.Consignee.Stop()) {
+		if i := lastOfType(route, model.StopTypeDelivery); i >= 0 {
+			stopFieldsOf(consignee).apply(&route[i])
+		}
+	}
+	return route
+}
+
+// apply sets the changeable fields of stop to f.
+func (f stopFields) apply(stop *model.CLStop) {
+	stop.ApptTime, stop.ReadyTime, stop.MustDeliver, stop.Timezone, stop.ApptNote = f.ApptTime, f.ReadyTime, f.MustDeliver, f.Timezone, f.Note
+}
+
+// firstOfType returns the index of the first stop of stopType, or -1.
+func firstOfType(stops []model.CLStop, stopType string) int {
+	for i, stop := range stops {
+		if stop.Type == stopType {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastOfType returns the index of the last stop of stopType, or -1.
+func lastOfType(stops []model.CLStop, stopType string) int {
+	for i := len(stops) - 1; i >= 0; i-- {
+		if stops[i].Type == stopType {
+			return i
+		}
+	}
+	return -1
+}
+
+type stopUpdate struct {
+	stop model.StopUpdate
+	at   time.Time
+}
+
+// stopChange returns the update that gives stop, which maps to base, the
+// changeable fields of want. A re-timed stop gets a new appointment and
+// planned window, checked against want's business hours; an appointment keeps
+// the flex it has in Turvo.
+func stopChange(stop model.RouteStop, base, want model.CLStop) (*stopUpdate, error) {
+	from, to := stopFieldsOf(base), stopFieldsOf(want)
+	change := &stopUpdate{stop: model.StopUpdate{ID: stop.ID, Operation: model.OperationUpdate}}
+	if from.retimed(to) {
+		target := base
+		to.apply(&target)
+		target.BusinessHours = want.BusinessHours
+		times, err := schedule.ForStop(target)
+		if err != nil {
+			return nil, err
+		}
+		appointment := appointmentFor(times)
+		if !times.HasWindow() && stop.PlannedAppointmentDate.SchedulingType.Key != lookup.Current().Scheduling.FCFS.Key {
+			appointment.Flex = stop.Appointment.Flex
+		}
+		schedulingType, planned := schedulingType(times), plannedWindow(times)
+		change.stop.Appointment = &appointment
+		change.stop.SchedulingType = &schedulingType
+		change.stop.PlannedAppointmentDate = &planned
+		change.at = times.Appt
+	}
+	if from.Note != to.Note {
+		note := to.Note
+		change.stop.Notes = &note
+	}
+	return change, nil
+}
+
+// shipmentDate is the start or end date to send when a stop was re-timed to at.
+func shipmentDate(at time.Time) *model.DateTime {
+	if at.IsZero() {
+		return nil
+	}
+	return &model.DateTime{Date: schedule.Format(at), TimeZone: at.Location().String()}
+}
+
+// equipmentChange returns want as an update of the shipment's first
+// equipment entry, or as an insert when it has none.
+func equipmentChange(current []model.Equipment, want model.Equipment) (model.Equipment, bool) {
+	if len(current) == 0 {
+		want.Operation = model.OperationInsert
+		return want, true
+	}
+	e := current[0]
+	if e.Type == want.Type && e.Size == want.Size && e.Weight == want.Weight && e.Temp == want.Temp && e.TempUnits == want.TempUnits {
+		return model.Equipment{}, false
+	}
+	e.Type = want.Type
+	e.Size = want.Size
+	e.Weight = want.Weight
+	e.Temp = want.Temp
+	e.TempUnits = want.TempUnits
+	e.Operation = model.OperationUpdate
+	return e, true
+}
+
+// customerOrderChange returns the update to the live customer order's items,
+// costs and PO numbers, or nil when none of them changed.
+func customerOrderChange(orders []model.CustomerOrderDetail, base, desired model.CreateLoadRequest) (*model.CustomerOrderUpdate, error) {
+	order, ok := liveCustomerOrder(orders)
+	if !ok {
+		if customerRateChanged(base, desired) || base.PoNums != desired.PoNums ||
+			base.InPalletCount != desired.InPalletCount || base.Specifications != desired.Specifications ||
+			!sameRates(base.RateData.Accessorials, desired.RateData.Accessorials) {
+			return nil, errors.New("load has no customer order")
+		}
+		return nil, nil
+	}
+
+	change := model.CustomerOrderUpdate{ID: order.ID, Operation: model.OperationUpdate}
+
+	if customerRateChanged(base, desired) {
+		lines, err := rating.CustomerLineItems(desired.RateData, desired.RouteMiles)
+		if err != nil {
+			return nil, err
+		}
+		changes, delta := lineItemChanges(order.Costs.LineItem, lines, rateLineKey)
+		change.Costs = &model.Costs{TotalAmount: order.Costs.TotalAmount + delta, LineItem: changes}
+	}
+
+	items, err := itemChanges(order.Items, base, desired)
+	if err != nil {
+		return nil, err
+	}
+	change.Items = items
+
+	ratesChanged := !sameRates(base.RateData.Accessorials, desired.RateData.Accessorials)
+	var charges []model.LineItem
+	if ratesChanged || base.Specifications != desired.Specifications {
+		// a charge must stay backed by its flag even when only the flag changes
+		charges, err = accessorial.Charges(desired.Specifications, desired.RateData.Accessorials)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if ratesChanged {
+		if change.Costs == nil {
+			change.Costs = &model.Costs{TotalAmount: order.Costs.TotalAmount}
+		}
+		lines, delta := lineItemChanges(order.Costs.LineItem, charges, chargeKey)
+		change.Costs.LineItem = append(change.Costs.LineItem, lines...)
+		change.Costs.TotalAmount += delta
+	}
+
+	change.ExternalIds = poNumberChanges(order.ExternalIds, splitPONumbers(desired.PoNums))
+
+	if change.Costs == nil && len(change.Items) == 0 && len(change.ExternalIds) == 0 {
+		return nil, nil
+	}
+	return &change, nil
+}
+
+// itemChanges applies pallet count, hazmat and temperature changes to the
+// order's items. A pallet count can only be changed on a single-item order.
+func itemChanges(items []model.Item, base, desired model.CreateLoadRequest) ([]model.Item, error) {
+	pallets := base.InPalletCount != desired.InPalletCount
+	hazmat := base.Specifications.Hazmat != desired.Specifications.Hazmat
+	temps := base.Specifications.MinTempFahrenheit != desired.Specifications.MinTempFahrenheit ||
+		base.Specifications.MaxTempFahrenheit != desired.Specifications.MaxTempFahrenheit
+	if !pallets && !hazmat && !temps {
+		return nil, nil
+	}
+	if len(items) == 0 {
+		return nil, errors.New("load has no items to update")
+	}
+	if pallets && len(items) > 1 {
+		return nil, fmt.Errorf("inPalletCount spans %d items and cannot be changed", len(items))
+	}
+
+	var out []model.Item
+	for i, item := range items {
+		if pallets {
+			item.HandlingQty = desired.InPalletCount
+		}
+		if hazmat && (i == 0 || !desired.Specifications.Hazmat) {
+			item.IsHazmat = desired.Specifications.Hazmat
+		}
+		if temps {
+			item.MinTemp = model.Temperature{Temp: desired.Specifications.MinTempFahrenheit, TempUnit: model.ValueKey{Key: "1510", Value: "°F"}}
+			item.MaxTemp = model.Temperature{Temp: desired.Specifications.MaxTempFahrenheit, TempUnit: model.ValueKey{Key: "1510", Value: "°F"}}
+		}
+		if item.HandlingQty == items[i].HandlingQty && item.IsHazmat == items[i].IsHazmat &&
+			item.MinTemp == items[i].MinTemp && item.MaxTemp == items[i].MaxTemp {
+			continue
+		}
+		item.Operation = model.OperationUpdate
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// poNumberChanges deletes purchase order IDs missing from want and inserts
+// the ones the order does not have yet.
+func poNumberChanges(current []model.ExternalID, want []string) []model.ExternalID {
+	wanted := map[string]bool{}
+	for _, po := range want {
+		wanted[po] = true
+	}
+
+	var out []model.ExternalID
+	have := map[string]bool{}
+	for _, ext := range current {
+		if ext.Type.Key != externalIDTypePO {
+			continue
+		}
+		have[ext.Value] = true
+		if !wanted[ext.Value] {
+			ext.Operation = model.OperationDelete
+			out = append(out, ext)
+		}
+	}
+	for _, po := range want {
+		if !have[po] {
+			have[po] = true
+			out = append(out, model.ExternalID{
+				Type:      model.ValueKey{Key: externalIDTypePO, Value: "Purchase order #"},
+				Value:     po,
+				Operation: model.OperationInsert,
+			})
+		}
+	}
+	return out
+}
+
+// customerRateChanged reports whether the customer line items must be rated
+// again to go from base to desired.
+func customerRateChanged(base, desired model.CreateLoadRequest) bool {
+	b, d := base.RateData, desired.RateData
+	return b.CustomerRateType != d.CustomerRateType || b.CustomerLhRateUsd != d.CustomerLhRateUsd ||
+		b.CustomerNumHours != d.CustomerNumHours || b.FscPercent != d.FscPercent ||
+		b.FscPerMile != d.FscPerMile || base.RouteMiles != desired.RouteMiles
+}
+
+// carrierRateChanged reports whether the carrier linehaul must be rated again
+// to go from base to desired.
+func carrierRateChanged(base, desired model.CreateLoadRequest) bool {
+	b, d := base.RateData, desired.RateData
+	perMile, _ := rating.ParseType(d.CarrierRateType)
+	return b.CarrierRateType != d.CarrierRateType || b.CarrierLhRateUsd != d.CarrierLhRateUsd ||
+		b.CarrierNumHours != d.CarrierNumHours || base.RouteMiles != desired.RouteMiles && perMile == rating.TypePerMile
+}
+
+// linehaulKey identifies the linehaul line item.
+func linehaulKey(li model.LineItem) (string, bool) {
+	_, ok := rating.LinehaulType(li)
+	return "linehaul", ok
+}
+
+// rateLineKey identifies the linehaul and fuel surcharge line items.
+func rateLineKey(li model.LineItem) (string, bool) {
+	if _, ok := rating.LinehaulType(li); ok {
+		return "linehaul", true
+	}
+	if rating.IsFuelSurcharge(li) {
+		return "fuel", true
+	}
+	return "", false
+}
+
+// chargeKey identifies accessorial line items by accessorial.
+func chargeKey(li model.LineItem) (string, bool) {
+	a, ok := accessorial.ForCharge(li)
+	return a.Name, ok
+}
+
+// lineItemChanges turns the current line items that key identifies into want,
+// returning the line item changes and the amount they add to the total.
+// Current line items key does not identify are left alone.
+func lineItemChanges(current, want []model.LineItem, key func(model.LineItem) (string, bool)) ([]model.LineItem, float64) {
+	existing := map[string]model.LineItem{}
+	for _, li := range current {
+		if k, ok := key(li); ok {
+			existing[k] = li
+		}
+	}
+
+	var changes []model.LineItem
+	var delta float64
+	for _, line := range want {
+		k, _ := key(line)
+		li, ok := existing[k]
+		delete(existing, k)
+		switch {
+		case !ok:
+			line.Operation = model.OperationInsert
+		case li.Code.Key == line.Code.Key && li.Amount == line.Amount && li.Price == line.Price && li.Notes == line.Notes:
+			continue
+		default:
+			line.ID = li.ID
+			line.Operation = model.OperationUpdate
+			delta -= li.Amount
+		}
+		changes = append(changes, line)
+		delta += line.Amount
+	}
+	for _, li := range current {
+		k, ok := key(li)
+		if _, stale := existing[k]; ok && stale {
+			delete(existing, k)
+			changes = append(changes, model.LineItem{ID: li.ID, Operation: model.OperationDelete})
+			delta -= li.Amount
+		}
+	}
+	return changes, delta
+}
+
+// sameRates reports whether two accessorial rate sets charge the same, treating
+// a zero rate like a missing one.
+func sameRates(a, b map[string]float64) bool {
+	for name, rate := range a {
+		if b[name] != rate {
+			return false
+		}
+	}
+	for name, rate := range b {
+		if a[name] != rate {
+			return false
+		}
+	}
+	return true
+}
+
+func liveCustomerOrder(orders []model.CustomerOrderDetail) (model.CustomerOrderDetail, bool) {
+	for _, order := range orders {
+		if !order.Deleted {
+			return order, true
+		}
+	}
+	return model.CustomerOrderDetail{}, false
+}
+
+func liveCarrierOrder(orders []model.CarrierOrderDetail) (model.CarrierOrderDetail, bool) {
+	for _, order := range orders {
+		if !order.Deleted {
+			return order, true
+		}
+	}
+	return model.CarrierOrderDetail{}, false
+}
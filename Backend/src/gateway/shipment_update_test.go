@@ -0,0 +1,298 @@
+package gateway
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/rating"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShipmentUpdateTestSuite struct {
+	suite.Suite
+	shipment model.ShipmentDetail
+}
+
+func (suite *ShipmentUpdateTestSuite) SetupTest() {
+	suite.shipment = model.ShipmentDetail{
+		ID:          500,
+		CustomID:    "M-500",
+		LTLShipment: false,
+		StartDate:   model.DateTime{Date: "2025-08-01T13:00:00Z", TimeZone: "America/Chicago"},
+		Status:      model.AvroStatus{Code: model.ValueKey{Key: "2102", Value: "Covered"}},
+		Equipment: []model.Equipment{{
+			ID:     61,
+			Type:   model.ValueKey{Key: "1200", Value: "Van"},
+			Weight: 20000,
+		}},
+		GlobalRoute: []model.RouteStop{
+			{ID: 71, GlobalRoute: model.GlobalRoute{
+				Name:        "Shipper",
+				StopType:    model.ValueKey{Key: "1500", Value: "Pickup"},
+				Timezone:    "America/Chicago",
+				Appointment: model.Appointment{Date: "2025-08-01T13:00:00Z", Timezone: "America/Chicago", Flex: 3600, HasTime: true},
+			}},
+			{ID: 72, GlobalRoute: model.GlobalRoute{
+				Name:        "Receiver",
+				StopType:    model.ValueKey{Key: "1501", Value: "Delivery"},
+				Appointment: model.Appointment{Date: "2025-08-03T15:00:00Z"},
+			}},
+		},
+		CustomerOrder: []model.CustomerOrderDetail{{ID: 81, CustomerOrderAvro: model.CustomerOrderAvro{
+			Customer: model.CustomerAvro{ID: 973069, Name: "Bunker"},
+			Items:    []model.Item{{ID: 91, Name: "Widgets", HandlingQty: 10}},
+			Costs: model.Costs{TotalAmount: 2100, LineItem: []model.LineItem{
+				{ID: 101, Code: model.ValueKey{Key: "1600", Value: "Freight - flat"}, Price: 2000, Amount: 2000, Billable: true},
+				{ID: 102, Code: model.ValueKey{Key: "1601", Value: "Fuel"}, Price: 100, Amount: 100, Billable: true},
+			}},
+			ExternalIds: []model.ExternalID{
+				{ID: 111, Type: model.ValueKey{Key: "1400"}, Value: "PO-1"},
+				{ID: 112, Type: model.ValueKey{Key: "1400"}, Value: "PO-2"},
+			},
+		}}},
+	}
+}
+
+func (suite *ShipmentUpdateTestSuite) desired(patch string) model.CreateLoadRequest {
+	load, err := model.LoadPatch(patch).Apply(LoadFromShipment(suite.shipment).CreateLoadRequest)
+	suite.Require().NoError(err)
+	return load
+}
+
+func (suite *ShipmentUpdateTestSuite) TestNoChanges() {
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"pickup":{"name":"Shipper"}}`), nil)
+	suite.Require().NoError(err)
+	suite.True(update.Empty())
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRetimesPickup() {
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"pickup":{"apptTime":"2025-08-02T09:30:00-05:00","apptNote":"call ahead"}}`), nil)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(update.GlobalRoute, 1)
+	stop := update.GlobalRoute[0]
+	suite.Equal(71, stop.ID)
+	suite.Equal(model.OperationUpdate, stop.Operation)
+	suite.Equal("2025-08-02T09:30:00-05:00", stop.Appointment.Date)
+	suite.Equal(3600, stop.Appointment.Flex)
+	suite.Equal("call ahead", *stop.Notes)
+	suite.Equal(&model.DateTime{Date: "2025-08-02T09:30:00-05:00", TimeZone: "America/Chicago"}, update.StartDate)
+	suite.Nil(update.EndDate)
+	suite.Empty(update.CustomerOrder)
+	suite.Empty(update.Equipment)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestOpensPickupWindow() {
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"pickup":{"readyTime":"2025-08-01T06:00:00-05:00"}}`), nil)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(update.GlobalRoute, 1)
+	stop := update.GlobalRoute[0]
+	suite.Equal(&model.Appointment{Date: "2025-08-01T06:00:00-05:00", Timezone: "America/Chicago", Flex: 7200, HasTime: true}, stop.Appointment)
+	fcfs := lookup.Default().Scheduling.FCFS
+	suite.Equal(&fcfs, stop.SchedulingType)
+	suite.Equal("2025-08-01T06:00:00-05:00", stop.PlannedAppointmentDate.Appointment.From.Date)
+	suite.Equal("2025-08-01T08:00:00-05:00", stop.PlannedAppointmentDate.Appointment.To.Date)
+	suite.Equal(&model.DateTime{Date: "2025-08-01T08:00:00-05:00", TimeZone: "America/Chicago"}, update.StartDate)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRetimesIntermediateStop() {
+	suite.shipment.GlobalRoute = append(suite.shipment.GlobalRoute, model.RouteStop{ID: 73, GlobalRoute: model.GlobalRoute{
+		Name:        "Crossdock",
+		Sequence:    1,
+		StopType:    model.ValueKey{Key: "1501", Value: "Delivery"},
+		Appointment: model.Appointment{Date: "2025-08-02T10:00:00Z"},
+	}})
+	suite.shipment.GlobalRoute[1].Sequence = 2
+
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"stops":[{},{"apptTime":"2025-08-02T06:00:00-05:00"},{}]}`), nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(update.GlobalRoute, 1)
+	suite.Equal(73, update.GlobalRoute[0].ID)
+	suite.Equal("2025-08-02T06:00:00-05:00", update.GlobalRoute[0].Appointment.Date)
+	suite.Nil(update.StartDate)
+	suite.Nil(update.EndDate)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRouteFor() {
+	base := LoadFromShipment(suite.shipment).CreateLoadRequest
+	desired := suite.desired(`{
+		"pickup": {"apptTime": "2025-08-01T09:00:00-05:00"},
+		"stops": [{"apptTime": "2025-08-01T11:00:00-05:00", "apptNote": "dock 4"}, {"apptNote": "call ahead"}]
+	}`)
+
+	route := RouteFor(base, desired)
+	suite.Require().Len(route, 2)
+	suite.Equal("2025-08-01T09:00:00-05:00", route[0].ApptTime, "the pickup object wins")
+	suite.Empty(route[0].ApptNote, "along with its note")
+	suite.Equal("2025-08-03T15:00:00Z", route[1].ApptTime)
+	suite.Equal("call ahead", route[1].ApptNote)
+	suite.Equal("2025-08-01T11:00:00-05:00", desired.Stops[0].ApptTime, "desired is not changed")
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRejectsStopChanges() {
+	_, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"stops":[{},{"name":"Elsewhere"}]}`), nil)
+	suite.ErrorContains(err, "stops[1].name")
+
+	_, err = ShipmentUpdateFor(suite.shipment, suite.desired(`{"stops":[{},{},{"type":"delivery","name":"Extra"}]}`), nil)
+	suite.ErrorContains(err, "the number of stops")
+
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"stops":[{"apptNote":"dock 4"}]}`), nil)
+	suite.Require().NoError(err, "a shorter stops array changes only the stops it lists")
+	suite.Require().Len(update.GlobalRoute, 1)
+	suite.Equal(71, update.GlobalRoute[0].ID)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestChangesRateAndPONumbers() {
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"rateData":{"customerLhRateUsd":2500},"poNums":"PO-2,PO-3"}`), nil)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(update.CustomerOrder, 1)
+	order := update.CustomerOrder[0]
+	suite.Equal(81, order.ID)
+	suite.Equal(model.OperationUpdate, order.Operation)
+	suite.Equal(2600.0, order.Costs.TotalAmount)
+	suite.Require().Len(order.Costs.LineItem, 1)
+	suite.Equal(101, order.Costs.LineItem[0].ID)
+	suite.Equal(2500.0, order.Costs.LineItem[0].Amount)
+	suite.Equal(model.OperationUpdate, order.Costs.LineItem[0].Operation)
+	suite.Empty(order.Items)
+
+	suite.Require().Len(order.ExternalIds, 2)
+	suite.Equal(111, order.ExternalIds[0].ID)
+	suite.Equal(model.OperationDelete, order.ExternalIds[0].Operation)
+	suite.Equal("PO-3", order.ExternalIds[1].Value)
+	suite.Equal(model.OperationInsert, order.ExternalIds[1].Operation)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestChangesEquipmentAndItems() {
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"totalWeight":9000,"inPalletCount":12,"specifications":{"minTempFahrenheit":34,"maxTempFahrenheit":38}}`), nil)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(update.Equipment, 1)
+	suite.Equal(61, update.Equipment[0].ID)
+	suite.Equal("1208", update.Equipment[0].Type.Key)
+	suite.Equal(9000, update.Equipment[0].Weight)
+	suite.Equal(34, update.Equipment[0].Temp)
+	suite.Equal(model.OperationUpdate, update.Equipment[0].Operation)
+	suite.Require().NotNil(update.LTLShipment)
+	suite.True(*update.LTLShipment)
+
+	items := update.CustomerOrder[0].Items
+	suite.Require().Len(items, 1)
+	suite.Equal(91, items[0].ID)
+	suite.Equal("Widgets", items[0].Name)
+	suite.Equal(12, items[0].HandlingQty)
+	suite.Equal(38, items[0].MaxTemp.Temp)
+	suite.Equal(model.OperationUpdate, items[0].Operation)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestChangesRateType() {
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(
+		`{"routeMiles":500,"rateData":{"customerRateType":"perMile","customerLhRateUsd":4,"fscPerMile":0.5}}`), nil)
+	suite.Require().NoError(err)
+
+	costs := update.CustomerOrder[0].Costs
+	suite.Equal(2350.0, costs.TotalAmount)
+	suite.Require().Len(costs.LineItem, 2)
+	suite.Equal(101, costs.LineItem[0].ID)
+	suite.Equal(model.OperationUpdate, costs.LineItem[0].Operation)
+	suite.Equal(model.ValueKey{Key: "1602", Value: "Freight - per mile"}, costs.LineItem[0].Code)
+	suite.Equal(500, costs.LineItem[0].Qty)
+	suite.Equal(2000.0, costs.LineItem[0].Amount)
+	suite.Equal(model.OperationInsert, costs.LineItem[1].Operation)
+	suite.Equal(250.0, costs.LineItem[1].Amount)
+
+	suite.shipment.CustomerOrder[0].Costs = model.Costs{TotalAmount: costs.TotalAmount, LineItem: []model.LineItem{
+		{ID: 101, Code: costs.LineItem[0].Code, Qty: 500, Price: 4, Amount: 2000},
+		{ID: 102, Code: model.ValueKey{Key: "1601", Value: "Fuel"}, Price: 100, Amount: 100},
+		{ID: 103, Code: costs.LineItem[1].Code, Qty: 500, Price: 0.5, Amount: 250},
+	}}
+	detail := LoadFromShipment(suite.shipment)
+	suite.Equal(rating.TypePerMile, detail.RateData.CustomerRateType)
+	suite.Equal(4.0, detail.RateData.CustomerLhRateUsd)
+	suite.Equal(0.5, detail.RateData.FscPerMile)
+	suite.Equal(500.0, detail.RouteMiles)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestChangesAccessorials() {
+	suite.shipment.GlobalRoute[1].Services = []model.ValueKey{{Key: "9000", Value: "Appointment required"}, {Key: "3100", Value: "Liftgate"}}
+	order := &suite.shipment.CustomerOrder[0].Costs
+	order.TotalAmount = 2175
+	order.LineItem = append(order.LineItem, model.LineItem{ID: 103, Code: model.ValueKey{Key: "1617", Value: "Liftgate"}, Amount: 75, Notes: "Liftgate - delivery"})
+
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(
+		`{"specifications":{"liftgateDelivery":false,"labor":true},"rateData":{"accessorials":{"liftgateDelivery":0,"labor":60}}}`), nil)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(update.GlobalRoute, 1)
+	suite.Equal(72, update.GlobalRoute[0].ID)
+	suite.Equal([]model.ValueKey{{Key: "9000", Value: "Appointment required"}, {Key: "3107", Value: "Labor"}}, *update.GlobalRoute[0].Services)
+
+	costs := update.CustomerOrder[0].Costs
+	suite.Equal(2160.0, costs.TotalAmount)
+	suite.Require().Len(costs.LineItem, 2)
+	suite.Equal(model.LineItem{Code: model.ValueKey{Key: "1624", Value: "Labor"}, Qty: 1, Price: 60, Amount: 60, Billable: true, Notes: "Labor"}, costs.LineItem[0])
+	suite.Equal(model.LineItem{ID: 103, Operation: model.OperationDelete}, costs.LineItem[1])
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRejectsChargeForDroppedAccessorial() {
+	suite.shipment.GlobalRoute[1].Services = []model.ValueKey{{Key: "3100", Value: "Liftgate"}}
+	suite.shipment.CustomerOrder[0].Costs.LineItem = append(suite.shipment.CustomerOrder[0].Costs.LineItem,
+		model.LineItem{ID: 103, Code: model.ValueKey{Key: "1617"}, Amount: 75, Notes: "Liftgate - delivery"})
+
+	_, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"specifications":{"liftgateDelivery":false}}`), nil)
+	suite.ErrorIs(err, ErrValidation)
+	suite.ErrorContains(err, "liftgateDelivery is charged but not requested")
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRejectsReadOnlyChanges() {
+	_, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"status":"Delivered","pickup":{"city":"Gary"}}`), nil)
+	suite.ErrorIs(err, ErrValidation)
+	suite.ErrorContains(err, "cannot change status, pickup.city")
+}
+
+func (suite *ShipmentUpdateTestSuite) TestChangesCarrierRateType() {
+	suite.shipment.CarrierOrder = []model.CarrierOrderDetail{{ID: 121, CarrierOrderAvro: model.CarrierOrderAvro{
+		Carrier: model.CarrierAvro{ID: 834187, Name: "Fast Freight"},
+		Costs: model.Costs{TotalAmount: 1600, LineItem: []model.LineItem{
+			{ID: 131, Code: model.ValueKey{Key: "1600", Value: "Freight - flat"}, Qty: 1, Price: 1500, Amount: 1500},
+			{ID: 132, Code: model.ValueKey{Key: "1699", Value: "Tolls"}, Qty: 1, Price: 100, Amount: 100},
+		}},
+	}}}
+
+	update, err := ShipmentUpdateFor(suite.shipment, suite.desired(
+		`{"rateData":{"carrierRateType":"hourly","carrierLhRateUsd":50,"carrierNumHours":10}}`), nil)
+	suite.Require().NoError(err)
+
+	suite.Require().Len(update.CarrierOrder, 1)
+	suite.Equal(121, update.CarrierOrder[0].ID)
+	costs := update.CarrierOrder[0].Costs
+	suite.Equal(600.0, costs.TotalAmount)
+	suite.Equal([]model.LineItem{{
+		ID: 131, Code: model.ValueKey{Key: "1603", Value: "Freight - hourly"}, Qty: 10, Price: 50, Amount: 500, Operation: model.OperationUpdate,
+	}}, costs.LineItem)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRejectsCarrierRateWithoutCarrier() {
+	_, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"rateData":{"carrierLhRateUsd":1500}}`), nil)
+	suite.ErrorIs(err, ErrValidation)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestChecksBusinessHoursOfRetimedStops() {
+	_, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"stops":[{"apptTime":"2025-08-02T09:00:00-05:00","businessHours":"Mon-Fri 07:00-15:00"}]}`), nil)
+	suite.ErrorContains(err, "outside businessHours")
+
+	_, err = ShipmentUpdateFor(suite.shipment, suite.desired(`{"stops":[{"apptTime":"2025-08-01T09:00:00-05:00","businessHours":"Mon-Fri 07:00-15:00"}]}`), nil)
+	suite.NoError(err)
+}
+
+func (suite *ShipmentUpdateTestSuite) TestRejectsBadAppointment() {
+	_, err := ShipmentUpdateFor(suite.shipment, suite.desired(`{"consignee":{"apptTime":"tomorrow"}}`), nil)
+	suite.ErrorIs(err, ErrValidation)
+}
+
+func TestShipmentUpdateTestSuite(t *testing.T) {
+	suite.Run(t, new(ShipmentUpdateTestSuite))
+}
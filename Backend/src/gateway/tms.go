@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/carrier"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
+)
+
+// DefaultProvider is used when no TMS provider is configured for a tenant.
+const DefaultProvider = "turvo"
+
+// TMS is the set of operations Drumkit needs from a transportation management system.
+type TMS interface {
+	RetrieveLoads(ctx context.Context, query model.LoadQuery) (model.ShipmentsDetails, error)
+	GetLoad(ctx context.Context, id int) (model.ShipmentDetail, error)
+	UpdateLoad(ctx context.Context, id int, update model.ShipmentUpdate) (model.ShipmentDetail, error)
+	UpdateStatus(ctx context.Context, id int, change model.StatusChange) (model.ShipmentDetail, error)
+	CreateLoad(ctx context.Context, load model.CreateLoadRequest, refs model.LoadRefs) (model.ShipmentDetail, error)
+	RetrieveLocations(ctx context.Context, query string) ([]locations.Location, error)
+	GetLocation(ctx context.Context, id int) (locations.Location, error)
+	CreateLocation(ctx context.Context, location locations.CreateRequest) (locations.Location, error)
+	RetrieveCustomers(ctx context.Context, query customer.Query) ([]customer.Customer, error)
+	RetrieveCarriers(ctx context.Context, query carrier.Query) ([]carrier.Carrier, error)
+}
+
+var _ TMS = (*TurvoAPIGateway)(nil)
+
+// Factory builds a TMS client, usually from environment configuration.
+type Factory func() (TMS, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+func init() {
+	Register(DefaultProvider, func() (TMS, error) {
+		gw := NewTurvoAPIGateway()
+		if gw == nil {
+			return nil, errors.New("turvo: missing TURVO_* credentials in environment")
+		}
+		return gw, nil
+	})
+}
+
+// Register makes a TMS provider available under name. Registering the same
+// name twice replaces the previous factory.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(name)] = factory
+}
+
+// Providers lists the registered provider names in sorted order.
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTMS builds the TMS registered under provider.
+func NewTMS(provider string) (TMS, error) {
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(provider)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown TMS provider %q (registered: %s)", provider, strings.Join(Providers(), ", "))
+	}
+	return factory()
+}
+
+// ProviderForTenant returns the configured provider for tenant. It checks
+// TMS_PROVIDER_<TENANT> first, then TMS_PROVIDER, then falls back to DefaultProvider.
+func ProviderForTenant(tenant string) string {
+	if tenant != "" {
+		key := "TMS_PROVIDER_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(tenant))
+		if provider := os.Getenv(key); provider != "" {
+			return provider
+		}
+	}
+	if provider := os.Getenv("TMS_PROVIDER"); provider != "" {
+		return provider
+	}
+	return DefaultProvider
+}
+
+// NewTMSForTenant builds the TMS configured for tenant.
+func NewTMSForTenant(tenant string) (TMS, error) {
+	return NewTMS(ProviderForTenant(tenant))
+}
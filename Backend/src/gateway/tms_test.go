@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TMSRegistryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TMSRegistryTestSuite) TestProviderForTenant() {
+	suite.T().Setenv("TMS_PROVIDER", "")
+	suite.Equal(DefaultProvider, ProviderForTenant("acme"))
+
+	suite.T().Setenv("TMS_PROVIDER", "mcleod")
+	suite.Equal("mcleod", ProviderForTenant("acme"))
+
+	suite.T().Setenv("TMS_PROVIDER_ACME_FREIGHT", "revenova")
+	suite.Equal("revenova", ProviderForTenant("acme-freight"))
+	suite.Equal("mcleod", ProviderForTenant("other"))
+}
+
+func (suite *TMSRegistryTestSuite) TestNewTMS_UsesRegisteredFactory() {
+	gw := &TurvoAPIGateway{Host: "https://turvo.test/v1"}
+	Register("Fake", func() (TMS, error) { return gw, nil })
+
+	tms, err := NewTMS("fake")
+	suite.Require().NoError(err)
+	suite.Same(gw, tms)
+	suite.Contains(Providers(), "fake")
+}
+
+func (suite *TMSRegistryTestSuite) TestNewTMS_UnknownProvider() {
+	_, err := NewTMS("does-not-exist")
+	suite.ErrorContains(err, `unknown TMS provider "does-not-exist"`)
+}
+
+func (suite *TMSRegistryTestSuite) TestNewTMS_TurvoRequiresCredentials() {
+	suite.T().Setenv("TURVO_BASE_URL", "")
+	_, err := NewTMS(DefaultProvider)
+	suite.ErrorContains(err, "missing TURVO_* credentials")
+}
+
+func TestTMSRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(TMSRegistryTestSuite))
+}
@@ -2,36 +2,29 @@ package gateway
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"drumkit.com/interview/src/accessorial"
+	"drumkit.com/interview/src/equipment"
+	"drumkit.com/interview/src/lookup"
 	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/carrier"
 	"drumkit.com/interview/src/model/customer"
 	locations "drumkit.com/interview/src/model/location"
+	"drumkit.com/interview/src/rating"
+	"drumkit.com/interview/src/schedule"
 )
 
-type AuthRequestBody struct {
-	GrantType string `json:"grant_type"`
-	Username  string `json:"username"`
-	Password  string `json:"password"`
-	Scope     string `json:"scope"`
-	Type      string `json:"type"`
-}
-
-type AuthResponseBody struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
-	Scope       string `json:"scope"`
-}
-
 type httpClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -40,66 +33,21 @@ type TurvoAPIGateway struct {
 	Host         string
 	Client       httpClient
 	Token        string
+	TokenExpiry  time.Time
 	APIKey       string
 	ClientID     string
 	ClientSecret string
 	Username     string
 	Password     string
-}
-
-// getAuthToken is a method so it can use values stored on the gateway instance.
-func (r *TurvoAPIGateway) getAuthToken() (string, error) {
-	tokenURL := r.Host + "/oauth/token"
-	requestBody, err := json.Marshal(AuthRequestBody{
-		GrantType: "password",
-		Username:  r.Username,
-		Password:  r.Password,
-		Scope:     "read+trust+write",
-		Type:      "business",
-	})
-	if err != nil {
-		return "", fmt.Errorf("error creating request body JSON: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", tokenURL, bytes.NewBuffer(requestBody))
-	if err != nil {
-		return "", fmt.Errorf("error creating HTTP request: %w", err)
-	}
-
-	req.Header.Set("x-api-key", r.APIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	q := req.URL.Query()
-	q.Add("client_id", r.ClientID)
-	q.Add("client_secret", r.ClientSecret)
-	req.URL.RawQuery = q.Encode()
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("error sending token request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading token response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to get token, status code: %d, response: %s", resp.StatusCode, string(body))
-	}
-
-	var tokenResponse AuthResponseBody
-	if err := json.Unmarshal(body, &tokenResponse); err != nil {
-		return "", fmt.Errorf("error parsing token response JSON: %w", err)
-	}
-
-	if tokenResponse.AccessToken == "" {
-		return "", fmt.Errorf("access token was empty in the response")
-	}
-
-	return tokenResponse.AccessToken, nil
+	// RefreshSkew is how long before TokenExpiry the token is proactively
+	// refreshed. Zero means defaultRefreshSkew.
+	RefreshSkew time.Duration
+	// Retry controls retries of failed calls. Zero fields use DefaultRetryPolicy.
+	Retry RetryPolicy
+
+	mu    sync.Mutex
+	now   func() time.Time
+	sleep func(time.Duration)
 }
 
 // NewTurvoAPIGateway reads credentials from environment variables (with sensible defaults)
@@ -132,7 +80,7 @@ func NewTurvoAPIGateway() *TurvoAPIGateway {
 
 	gw := &TurvoAPIGateway{
 		Host:         host,
-		Client:       &http.Client{},
+		Client:       &http.Client{Timeout: 30 * time.Second},
 		APIKey:       apiKey,
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
@@ -140,56 +88,147 @@ func NewTurvoAPIGateway() *TurvoAPIGateway {
 		Password:     password,
 	}
 
-	token, err := gw.getAuthToken()
-	if err != nil {
+	if _, err := gw.accessToken(context.Background()); err != nil {
 		panic(fmt.Sprintf("failed to authenticate: %v", err))
 	}
-	gw.Token = token
 
 	return gw
 }
-func (r *TurvoAPIGateway) RetrieveLoads(start string, pageSize string) ([]model.Shipment, error) {
-	u, err := url.Parse(fmt.Sprintf("%s/shipments/list", r.Host))
+
+// UpdateStatus moves a shipment to a new status through Turvo's status API
+// and returns the shipment as Turvo stored it.
+func (r *TurvoAPIGateway) UpdateStatus(ctx context.Context, id int, change model.StatusChange) (model.ShipmentDetail, error) {
+	requestBody, err := json.Marshal(model.StatusUpdateRequest{
+		Status: model.AvroStatus{
+			Code:        model.ValueKey{Key: string(change.Status), Value: change.Status.Name()},
+			Notes:       change.Reason,
+			Description: change.Status.Name(),
+		},
+	})
 	if err != nil {
-		return nil, err
+		return model.ShipmentDetail{}, fmt.Errorf("error marshalling StatusUpdateRequest: %w", err)
 	}
 
-	q := u.Query()
-	if start != "" {
-		q.Set("start", start)
+	url := fmt.Sprintf("%s/shipments/status/%d", r.Host, id)
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return model.ShipmentDetail{}, fmt.Errorf("error creating HTTP request: %w", err)
 	}
-	if pageSize != "" {
-		q.Set("pageSize", pageSize)
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// setting the same status twice is harmless, so every transient failure is retried
+	resp, err := r.execute(req, true)
+	if err != nil {
+		return model.ShipmentDetail{}, requestError("update shipment status", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return model.ShipmentDetail{}, responseError("update shipment status", resp)
+	}
+
+	var shipmentResp model.ShipmentDetailResponse
+	if err := json.NewDecoder(resp.Body).Decode(&shipmentResp); err != nil {
+		return model.ShipmentDetail{}, err
 	}
-	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	return shipmentResp.Details, nil
+}
+
+// RetrieveLoads fetches one page of shipments together with Turvo's pagination metadata.
+func (r *TurvoAPIGateway) RetrieveLoads(ctx context.Context, query model.LoadQuery) (model.ShipmentsDetails, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/shipments/list", r.Host))
 	if err != nil {
-		return nil, err
+		return model.ShipmentsDetails{}, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+r.Token)
-	req.Header.Set("x-api-key", r.APIKey)
+	u.RawQuery = encodeLoadQuery(query)
 
-	resp, err := r.Client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
-		return nil, err
+		return model.ShipmentsDetails{}, err
+	}
+
+	resp, err := r.execute(req, true)
+	if err != nil {
+		return model.ShipmentsDetails{}, requestError("list shipments", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+		return model.ShipmentsDetails{}, responseError("list shipments", resp)
 	}
 
 	var shipmentsResp model.ShipmentsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&shipmentsResp); err != nil {
-		return nil, err
+		return model.ShipmentsDetails{}, err
+	}
+
+	return shipmentsResp.Details, nil
+}
+
+// GetLoad fetches a single shipment with its route, equipment and orders.
+func (r *TurvoAPIGateway) GetLoad(ctx context.Context, id int) (model.ShipmentDetail, error) {
+	url := fmt.Sprintf("%s/shipments/%d", r.Host, id)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return model.ShipmentDetail{}, err
+	}
+
+	resp, err := r.execute(req, true)
+	if err != nil {
+		return model.ShipmentDetail{}, requestError("get shipment", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return model.ShipmentDetail{}, responseError("get shipment", resp)
+	}
+
+	var shipmentResp model.ShipmentDetailResponse
+	if err := json.NewDecoder(resp.Body).Decode(&shipmentResp); err != nil {
+		return model.ShipmentDetail{}, err
+	}
+
+	return shipmentResp.Details, nil
+}
+
+// UpdateLoad sends a partial update for a shipment and returns the shipment
+// as Turvo stored it.
+func (r *TurvoAPIGateway) UpdateLoad(ctx context.Context, id int, update model.ShipmentUpdate) (model.ShipmentDetail, error) {
+	requestBody, err := json.Marshal(update)
+	if err != nil {
+		return model.ShipmentDetail{}, fmt.Errorf("error marshalling ShipmentUpdate: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/shipments/%d", r.Host, id)
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return model.ShipmentDetail{}, fmt.Errorf("error creating HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// inserts are not idempotent, so only rate limiting is retried
+	resp, err := r.execute(req, false)
+	if err != nil {
+		return model.ShipmentDetail{}, requestError("update shipment", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return model.ShipmentDetail{}, responseError("update shipment", resp)
 	}
 
-	return shipmentsResp.Details.Shipments, nil
+	var shipmentResp model.ShipmentDetailResponse
+	if err := json.NewDecoder(resp.Body).Decode(&shipmentResp); err != nil {
+		return model.ShipmentDetail{}, err
+	}
+
+	return shipmentResp.Details, nil
 }
-func (r *TurvoAPIGateway) RetrieveLocations(query string) ([]locations.Location, error) {
+func (r *TurvoAPIGateway) RetrieveLocations(ctx context.Context, query string) ([]locations.Location, error) {
 	u, err := url.Parse(fmt.Sprintf("%s/locations/list", r.Host))
 	if err != nil {
 		return nil, err
@@ -202,23 +241,19 @@ func (r *TurvoAPIGateway) RetrieveLocations(query string) ([]locations.Location,
 		u.RawQuery = ""
 	}
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+r.Token)
-	req.Header.Set("x-api-key", r.APIKey)
-
-	resp, err := r.Client.Do(req)
+	resp, err := r.execute(req, true)
 	if err != nil {
-		return nil, err
+		return nil, requestError("list locations", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+		return nil, responseError("list locations", resp)
 	}
 
 	var locationsResp locations.LocationsResponse
@@ -229,35 +264,100 @@ func (r *TurvoAPIGateway) RetrieveLocations(query string) ([]locations.Location,
 	return locationsResp.Details.Locations, nil
 }
 
-func (r *TurvoAPIGateway) RetrieveCustomers(query string) ([]customer.Customer, error) {
-	u, err := url.Parse(fmt.Sprintf("%s/customers/list", r.Host))
+// GetLocation fetches a single location with its addresses and notes.
+func (r *TurvoAPIGateway) GetLocation(ctx context.Context, id int) (locations.Location, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/locations/%d", r.Host, id), nil)
 	if err != nil {
-		return nil, err
+		return locations.Location{}, err
 	}
 
-	if query != "" {
-		u.RawQuery = fmt.Sprintf("name[in]=[%s]", url.QueryEscape(query))
-	} else {
-		u.RawQuery = ""
+	resp, err := r.execute(req, true)
+	if err != nil {
+		return locations.Location{}, requestError("get location", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return locations.Location{}, responseError("get location", resp)
+	}
+
+	var locationResp locations.LocationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&locationResp); err != nil {
+		return locations.Location{}, err
+	}
+	return locationResp.Details, nil
+}
+
+// CreateLocation creates a location in Turvo and returns it with its new ID.
+func (r *TurvoAPIGateway) CreateLocation(ctx context.Context, location locations.CreateRequest) (locations.Location, error) {
+	body, err := json.Marshal(location)
+	if err != nil {
+		return locations.Location{}, fmt.Errorf("error marshalling location: %w", err)
 	}
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/locations", r.Host), bytes.NewBuffer(body))
+	if err != nil {
+		return locations.Location{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.execute(req, false)
+	if err != nil {
+		return locations.Location{}, requestError("create location", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return locations.Location{}, responseError("create location", resp)
+	}
+
+	var locationResp locations.LocationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&locationResp); err != nil {
+		return locations.Location{}, err
+	}
+	return locationResp.Details, nil
+}
+
+// RetrieveCustomers lists the customers matching every non-empty field of query.
+func (r *TurvoAPIGateway) RetrieveCustomers(ctx context.Context, query customer.Query) ([]customer.Customer, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/customers/list", r.Host))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+r.Token)
-	req.Header.Set("x-api-key", r.APIKey)
+	// keep the literal brackets in the final URL, escape the values
+	var filters []string
+	if query.ID != 0 {
+		filters = append(filters, fmt.Sprintf("id[eq]=%d", query.ID))
+	}
+	if v := strings.TrimSpace(query.Name); v != "" {
+		filters = append(filters, fmt.Sprintf("name[in]=[%s]", url.QueryEscape(v)))
+	}
+	for _, f := range []struct{ key, value string }{
+		{"externalIds.value", query.ExternalID},
+		{"address.city", query.City},
+		{"address.state", query.State},
+		{"address.zip", query.Zip},
+	} {
+		if v := strings.TrimSpace(f.value); v != "" {
+			filters = append(filters, fmt.Sprintf("%s[eq]=%s", f.key, url.QueryEscape(v)))
+		}
+	}
+	u.RawQuery = strings.Join(filters, "&")
 
-	resp, err := r.Client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
+
+	resp, err := r.execute(req, true)
+	if err != nil {
+		return nil, requestError("list customers", err)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+		return nil, responseError("list customers", resp)
 	}
 
 	var customersResp customer.CustomersResponse
@@ -268,51 +368,96 @@ func (r *TurvoAPIGateway) RetrieveCustomers(query string) ([]customer.Customer,
 	return customersResp.Details.Customers, nil
 }
 
-func (r *TurvoAPIGateway) CreateLoad(loads []model.CreateLoadRequest, pickUpId, deliveryId int) error {
-	for _, load := range loads {
-		avroLoadRequest, err := transformCreateLoadRequestToAvro(load, pickUpId, deliveryId)
-		if err != nil {
-			return fmt.Errorf("failed to transform load request: %w", err)
-		}
+// RetrieveCarriers lists the carriers matching every non-empty field of query.
+func (r *TurvoAPIGateway) RetrieveCarriers(ctx context.Context, query carrier.Query) ([]carrier.Carrier, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/carriers/list", r.Host))
+	if err != nil {
+		return nil, err
+	}
 
-		requestBody, err := json.Marshal(avroLoadRequest)
-		if err != nil {
-			return fmt.Errorf("error marshalling AvroLoadRequest: %w", err)
+	// keep the literal brackets in the final URL, escape the values
+	var filters []string
+	if query.ID != 0 {
+		filters = append(filters, fmt.Sprintf("id[eq]=%d", query.ID))
+	}
+	for _, f := range []struct{ key, value string }{
+		{"dotNumber", query.DotNumber},
+		{"mcNumber", query.McNumber},
+		{"scac", query.Scac},
+	} {
+		if v := strings.TrimSpace(f.value); v != "" {
+			filters = append(filters, fmt.Sprintf("%s[eq]=%s", f.key, url.QueryEscape(v)))
 		}
+	}
+	u.RawQuery = strings.Join(filters, "&")
 
-		url := fmt.Sprintf("%s/shipments", r.Host)
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
-		if err != nil {
-			return fmt.Errorf("error creating HTTP request: %w", err)
-		}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
 
-		req.Header.Set("Authorization", "Bearer "+r.Token)
-		req.Header.Set("x-api-key", r.APIKey)
-		req.Header.Set("Content-Type", "application/json")
+	resp, err := r.execute(req, true)
+	if err != nil {
+		return nil, requestError("list carriers", err)
+	}
+	defer resp.Body.Close()
 
-		resp, err := r.Client.Do(req)
-		if err != nil {
-			return fmt.Errorf("error sending create load request: %w", err)
-		}
-		defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError("list carriers", resp)
+	}
 
-		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("failed to create load, status code: %d, response: %s", resp.StatusCode, string(body))
-		}
+	var carriersResp carrier.CarriersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&carriersResp); err != nil {
+		return nil, err
 	}
-	return nil
+
+	return carriersResp.Details.Carriers, nil
 }
 
-func transformCreateLoadRequestToAvro(input model.CreateLoadRequest, pickUpId, deliveryId int) (model.AvroLoadRequest, error) {
+// CreateLoad creates a shipment for load and returns it as Turvo stored it.
+func (r *TurvoAPIGateway) CreateLoad(ctx context.Context, load model.CreateLoadRequest, refs model.LoadRefs) (model.ShipmentDetail, error) {
+	avroLoadRequest, err := transformCreateLoadRequestToAvro(load, refs)
+	if err != nil {
+		return model.ShipmentDetail{}, newValidationError("create shipment", fmt.Errorf("failed to transform load request: %w", err))
+	}
+
+	requestBody, err := json.Marshal(avroLoadRequest)
+	if err != nil {
+		return model.ShipmentDetail{}, fmt.Errorf("error marshalling AvroLoadRequest: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/shipments", r.Host)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return model.ShipmentDetail{}, fmt.Errorf("error creating HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
 
-	pickupTime, err := time.Parse(time.RFC3339, input.Pickup.ApptTime)
+	resp, err := r.execute(req, false)
 	if err != nil {
-		return model.AvroLoadRequest{}, fmt.Errorf("failed to parse pickup apptTime: %w", err)
+		return model.ShipmentDetail{}, requestError("create shipment", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return model.ShipmentDetail{}, responseError("create shipment", resp)
 	}
-	consigneeTime, err := time.Parse(time.RFC3339, input.Consignee.ApptTime)
+
+	var shipmentResp model.ShipmentDetailResponse
+	if err := json.NewDecoder(resp.Body).Decode(&shipmentResp); err != nil {
+		return model.ShipmentDetail{}, fmt.Errorf("error decoding created shipment: %w", err)
+	}
+	return shipmentResp.Details, nil
+}
+
+func transformCreateLoadRequestToAvro(input model.CreateLoadRequest, refs model.LoadRefs) (model.AvroLoadRequest, error) {
+	stops, err := input.RouteStops()
 	if err != nil {
-		return model.AvroLoadRequest{}, fmt.Errorf("failed to parse consignee apptTime: %w", err)
+		return model.AvroLoadRequest{}, err
+	}
+	if len(refs.LocationIDs) != len(stops) {
+		return model.AvroLoadRequest{}, fmt.Errorf("got %d location IDs for %d stops", len(refs.LocationIDs), len(stops))
 	}
 	statusCode, ok := model.StatusCodeForValue(input.Status)
 	if !ok {
@@ -320,98 +465,104 @@ func transformCreateLoadRequestToAvro(input model.CreateLoadRequest, pickUpId, d
 
 	}
 	ltlShipment := false
-	if input.TotalWeight < 15000 {
+	if input.TotalWeight < ltlWeightLimit {
 		ltlShipment = true
 	}
 
-	// determine equipment type from specifications
-	equipTypeKey := "1200"
-	equipTypeValue := "Van"
-	isReefer := false
-
-	if input.Specifications.MinTempFahrenheit != 0 || input.Specifications.MaxTempFahrenheit != 0 {
-		equipTypeKey = "1208"
-		equipTypeValue = "Refrigerated"
-		isReefer = true
-	} else if input.Specifications.Tarps || input.Specifications.Oversized {
-		equipTypeKey = "1204"
-		equipTypeValue = "Flatbed"
-	}
-
-	// build equipment entry
-	equipment := model.Equipment{
-		Operation: 0,
-		Type:      model.ValueKey{Key: equipTypeKey, Value: equipTypeValue},
-		Size:      model.ValueKey{Key: "1000", Value: "53ft"},
-		Weight:    int(input.TotalWeight),
-		WeightUnits: model.ValueKey{
-			Key:   "1520",
-			Value: "lb",
-		},
+	var loadEquipment model.Equipment
+	if refs.Equipment != nil {
+		loadEquipment = *refs.Equipment
+	} else {
+		loadEquipment = equipment.Default().Map(input)
 	}
 
-	// if reefer, include temp fields
-	if isReefer {
-		// prefer minTemp if present, otherwise maxTemp
-		var tempVal float64
-		if input.Specifications.MinTempFahrenheit != 0 {
-			tempVal = float64(input.Specifications.MinTempFahrenheit)
-		} else {
-			tempVal = float64(input.Specifications.MaxTempFahrenheit)
-		}
-		// set temp and temp units if the model supports them
-		// (Temp and TempUnits fields must exist on model.Equipment)
-		equipment.Temp = int(tempVal)
-		equipment.TempUnits = model.ValueKey{Key: "1510", Value: "°F"}
-	}
-	laneStart := input.Pickup.City + ", " + input.Pickup.State
-	laneEnd := input.Consignee.City + ", " + input.Consignee.State
+	first, last := stops[0], stops[len(stops)-1]
+	laneStart := first.City + ", " + first.State
+	laneEnd := last.City + ", " + last.State
 
 	// transform poNums -> []string
 	poNumbers := splitPONumbers(input.PoNums)
 
-	// build global route pickup stop
-	pickupStop := model.GlobalRoute{
-		StopType: model.ValueKey{Key: "1500", Value: "Pickup"},
-		Name:     input.Pickup.Name,
-		Location: model.Location{ID: pickUpId},
-		Sequence: 0,
-		Appointment: model.Appointment{
-			Date:     pickupTime.Format(time.RFC3339),
-			Flex:     3600,
-			Timezone: input.Pickup.Timezone,
-			HasTime:  true,
-		},
-		PoNumbers:     poNumbers,
-		Notes:         input.Pickup.ApptNote,
-		CustomerOrder: []model.GlobalRouteCustomerOrder{}, // map if you have customerOrder references in input
-		CarrierOrder:  []model.GlobalRouteCarrierOrder{},  // map if you have carrierOrder references in input
-	}
-
-	// build global route delivery stop (basic mapping)
-	_ = model.GlobalRoute{
-		StopType: model.ValueKey{Key: "1501", Value: "Delivery"},
-		Name:     input.Consignee.Name,
-		Location: model.Location{ID: deliveryId},
-		Sequence: 1,
-		Appointment: model.Appointment{
-			Date:     consigneeTime.Format(time.RFC3339),
-			Flex:     3600,
-			Timezone: input.Consignee.Timezone,
-			HasTime:  true,
-		},
-		PoNumbers:     poNumbers,
-		Notes:         input.Consignee.ApptNote,
-		CustomerOrder: []model.GlobalRouteCustomerOrder{},
-		CarrierOrder:  []model.GlobalRouteCarrierOrder{},
-	}
-	// Convert ExternalTMSId from string to int
-	externalTMSId := 0
-	if input.Customer.ExternalTMSId != "" {
-		if id, err := strconv.Atoi(input.Customer.ExternalTMSId); err == nil {
-			externalTMSId = id
+	if refs.CustomerID == 0 {
+		return model.AvroLoadRequest{}, errors.New("no customer ID")
+	}
+	customerName := refs.CustomerName
+	if customerName == "" {
+		customerName = input.Customer.Name
+	}
+
+	// the carrier order, when there is one, is linked from every stop
+	carrierOrders := []model.CarrierOrderAvro{}
+	routeCarrierOrders := []model.GlobalRouteCarrierOrder{}
+	if refs.CarrierID != 0 {
+		carrierOrder, err := carrierOrderFor(input, refs)
+		if err != nil {
+			return model.AvroLoadRequest{}, err
 		}
+		carrierOrders = append(carrierOrders, carrierOrder)
+		routeCarrierOrders = append(routeCarrierOrders, model.GlobalRouteCarrierOrder{
+			CarrierID:            refs.CarrierID,
+			CarrierOrderSourceID: refs.CarrierID,
+		})
+	}
+
+	// build one global route entry per stop; items reference the stops they
+	// are picked up at and delivered to by source ID
+	route := make([]model.GlobalRoute, 0, len(stops))
+	var pickupLocations, deliveryLocations []model.ItemLocation
+	var start, end schedule.Times
+	for i, stop := range stops {
+		times, err := schedule.ForStop(stop)
+		if err != nil {
+			return model.AvroLoadRequest{}, fmt.Errorf("stop %d: %w", i+1, err)
+		}
+		if i == 0 {
+			start = times
+		}
+		end = times
+		zone := times.Zone.String()
+
+		sourceID := strconv.Itoa(i + 1)
+		stopType := model.ValueKey{Key: stopTypePickup, Value: "Pickup"}
+		itemLocation := model.ItemLocation{GlobalShipLocationSourceID: sourceID, Name: stop.Name}
+		if stop.Type == model.StopTypeDelivery {
+			stopType = model.ValueKey{Key: stopTypeDelivery, Value: "Delivery"}
+			deliveryLocations = append(deliveryLocations, itemLocation)
+		} else {
+			pickupLocations = append(pickupLocations, itemLocation)
+		}
+
+		route = append(route, model.GlobalRoute{
+			GlobalShipLocationSourceID: sourceID,
+			StopType:                   stopType,
+			Name:                       stop.Name,
+			Location:                   model.Location{ID: refs.LocationIDs[i]},
+			Sequence:                   i,
+			Timezone:                   zone,
+			SchedulingType:             schedulingType(times),
+			Appointment:                appointmentFor(times),
+			PlannedAppointmentDate:     plannedWindow(times),
+			Services:                   accessorial.StopServices(input.Specifications, stops, i),
+			PoNumbers:                  poNumbers,
+			Notes:                      stop.ApptNote,
+			CustomerOrder: []model.GlobalRouteCustomerOrder{
+				{CustomerID: refs.CustomerID, CustomerOrderSourceID: refs.CustomerID},
+			},
+			CarrierOrder: routeCarrierOrders,
+		})
 	}
+	// the customer pays the linehaul and fuel surcharge plus any accessorial charges
+	rateLines, err := rating.CustomerLineItems(input.RateData, input.RouteMiles)
+	if err != nil {
+		return model.AvroLoadRequest{}, err
+	}
+	charges, err := accessorial.Charges(input.Specifications, input.RateData.Accessorials)
+	if err != nil {
+		return model.AvroLoadRequest{}, err
+	}
+	lineItems := append(rateLines, charges...)
+	customerCosts := model.Costs{TotalAmount: rating.Total(lineItems), LineItem: lineItems}
+
 	// build externalIds from poNumbers -> []model.ExternalID
 	var externalIDs []model.ExternalID
 	if len(poNumbers) > 0 {
@@ -428,12 +579,12 @@ func transformCreateLoadRequestToAvro(input model.CreateLoadRequest, pickUpId, d
 	avroRequest := model.AvroLoadRequest{
 		LTLShipment: ltlShipment,
 		StartDate: model.DateTime{
-			Date:     pickupTime.Format("2006-01-02T15:04:05Z"),
-			TimeZone: "America/Chicago",
+			Date:     schedule.Format(start.Appt),
+			TimeZone: start.Zone.String(),
 		},
 		EndDate: model.DateTime{
-			Date:     consigneeTime.Format("2006-01-02T15:04:05Z"),
-			TimeZone: "America/Chicago",
+			Date:     schedule.Format(end.Appt),
+			TimeZone: end.Zone.String(),
 		},
 
 		Status: model.AvroStatus{
@@ -453,21 +604,23 @@ func transformCreateLoadRequestToAvro(input model.CreateLoadRequest, pickUpId, d
 		},
 		// ! Contributors: []model.Contributor will not be used!
 		Lane:        model.Lane{Start: laneStart, End: laneEnd},
-		Equipment:   []model.Equipment{equipment},
-		GlobalRoute: []model.GlobalRoute{pickupStop},
+		Equipment:   []model.Equipment{loadEquipment},
+		GlobalRoute: route,
 		CustomerOrder: []model.CustomerOrderAvro{
 			{
-				CustomerOrderSourceID: externalTMSId,
+				CustomerOrderSourceID: refs.CustomerID,
 				Customer: model.CustomerAvro{
-					ID:   externalTMSId,
-					Name: input.Customer.Name,
+					ID:   refs.CustomerID,
+					Name: customerName,
 				},
 				Items: []model.Item{
 					{
-						Name:         "Proof of Concept Item",
-						HandlingQty:  input.InPalletCount,
-						HandlingUnit: model.ValueKey{Key: "35210", Value: "Pallets"},
-						IsHazmat:     input.Specifications.Hazmat,
+						Name:             "Proof of Concept Item",
+						HandlingQty:      input.InPalletCount,
+						PickupLocation:   pickupLocations,
+						DeliveryLocation: deliveryLocations,
+						HandlingUnit:     model.ValueKey{Key: "35210", Value: "Pallets"},
+						IsHazmat:         input.Specifications.Hazmat,
 						MinTemp: model.Temperature{
 							Temp: input.Specifications.MinTempFahrenheit,
 							TempUnit: model.ValueKey{
@@ -484,26 +637,90 @@ func transformCreateLoadRequestToAvro(input model.CreateLoadRequest, pickUpId, d
 						},
 					},
 				},
-				Costs: model.Costs{
-					TotalAmount: input.RateData.CustomerLhRateUsd,
-					LineItem: []model.LineItem{
-						{
-
-							Code:      model.ValueKey{Key: "1600", Value: "Freight - flat"},
-							Price:     input.RateData.CustomerLhRateUsd,
-							Amount:    input.RateData.CustomerLhRateUsd,
-							Billable:  true,
-							Operation: 0,
-						},
-					},
-				},
+				Costs:       customerCosts,
 				ExternalIds: externalIDs,
 			},
 		},
+		CarrierOrder: carrierOrders,
 	}
 	return avroRequest, nil
 }
 
+// schedulingType is first come, first served for a stop with a window and
+// by appointment otherwise.
+func schedulingType(times schedule.Times) model.ValueKey {
+	if times.HasWindow() {
+		return lookup.Current().Scheduling.FCFS
+	}
+	return lookup.Current().Scheduling.Appointment
+}
+
+// appointmentFor is the appointment of a stop: the exact time when it is by
+// appointment, or the start of its window with the window's length as flex.
+func appointmentFor(times schedule.Times) model.Appointment {
+	appointment := model.Appointment{
+		Date:     schedule.Format(times.Appt),
+		Timezone: times.Zone.String(),
+		HasTime:  true,
+	}
+	if times.HasWindow() {
+		appointment.Date = schedule.Format(times.From)
+		appointment.Flex = int(times.To.Sub(times.From).Seconds())
+	}
+	return appointment
+}
+
+// plannedWindow is the planned appointment window of a stop, which is just
+// the appointment for a stop without a window.
+func plannedWindow(times schedule.Times) model.PlannedAppointmentDate {
+	from, to := times.Appt, times.Appt
+	if times.HasWindow() {
+		from, to = times.From, times.To
+	}
+	zone := times.Zone.String()
+	return model.PlannedAppointmentDate{
+		SchedulingType: schedulingType(times),
+		Appointment: model.PlannedAppointmentWindow{
+			From: model.Appointment{Date: schedule.Format(from), Timezone: zone, HasTime: true},
+			To:   model.Appointment{Date: schedule.Format(to), Timezone: zone, HasTime: true},
+		},
+	}
+}
+
+// carrierOrderFor builds the carrier order for the matched carrier in refs,
+// with the request's drivers and the carrier linehaul rated flat, per mile or
+// hourly. The dispatch and confirmation times and the truck and trailer IDs
+// are not sent; Turvo records them as the load is dispatched and tracked.
+func carrierOrderFor(input model.CreateLoadRequest, refs model.LoadRefs) (model.CarrierOrderAvro, error) {
+	name := refs.CarrierName
+	if name == "" {
+		name = input.Carrier.Name
+	}
+
+	drivers := []model.Driver{}
+	for _, d := range []struct{ name, phone string }{
+		{input.Carrier.FirstDriverName, input.Carrier.FirstDriverPhone},
+		{input.Carrier.SecondDriverName, input.Carrier.SecondDriverPhone},
+	} {
+		if strings.TrimSpace(d.name) == "" {
+			continue
+		}
+		drivers = append(drivers, model.Driver{Name: d.name, Phone: d.phone})
+	}
+
+	lineItems, err := rating.CarrierLineItems(input.RateData, input.RouteMiles)
+	if err != nil {
+		return model.CarrierOrderAvro{}, err
+	}
+
+	return model.CarrierOrderAvro{
+		CarrierOrderSourceID: refs.CarrierID,
+		Carrier:              model.CarrierAvro{ID: refs.CarrierID, Name: name},
+		Drivers:              drivers,
+		Costs:                model.Costs{TotalAmount: rating.Total(lineItems), LineItem: lineItems},
+	}, nil
+}
+
 // splitPONumbers transforms a comma-separated poNums string into []string
 func splitPONumbers(poNums string) []string {
 	if strings.TrimSpace(poNums) == "" {
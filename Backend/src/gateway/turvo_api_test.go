@@ -1,137 +1,476 @@
 package gateway
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"net/url"
 	"testing"
 
+	"drumkit.com/interview/src/lookup"
 	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/carrier"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
+	"drumkit.com/interview/src/rating"
+	"drumkit.com/interview/src/test/faketurvo"
 	"github.com/stretchr/testify/suite"
 )
 
-// mockClient implements http.Client's Do method for testing
-// type mockClient struct {
-// 	resp *http.Response
-// 	err  error
-// }
-
-// func (m *mockClient) Do(req *http.Request) (*http.Response, error) {
-// 	return m.resp, m.err
-// }
-
-// func TestRetrieveLoads_Success(t *testing.T) {
-// 	// mockLoads := `[{"id":"123","status":"active"},{"id":"456","status":"inactive"}]`
-// 	// mockResp := &http.Response{
-// 	// 	StatusCode: http.StatusOK,
-// 	// 	Body:       ioutil.NopCloser(bytes.NewBufferString(mockLoads)),
-// 	// }
-// 	client := &http.Client{}
-// 	repo := &TurvoAPIRepository{
-// 		Host:   "https://my-sandbox-publicapi.turvo.com/v1",
-// 		Client: client,
-// 	}
-
-// 	loads, err := repo.RetrieveLoads()
-// 	if err != nil {
-// 		t.Fatalf("expected no error, got %v", err)
-// 	}
-// 	if len(loads) != 2 {
-// 		t.Errorf("expected 2 loads, got %d", len(loads))
-// 	}
-// 	if loads[0].ID != "123" || loads[1].ID != "456" {
-// 		t.Errorf("unexpected load IDs: %+v", loads)
-// 	}
-// }
-
 type TurvoAPITestSuite struct {
 	suite.Suite
-	gw *TurvoAPIGateway
+	turvo *faketurvo.Server
+	gw    *TurvoAPIGateway
 }
 
-func (suite *TurvoAPITestSuite) SetupSuite() {
-	os.Setenv("TURVO_BASE_URL", "https://my-sandbox-publicapi.turvo.com/v1")
-	os.Setenv("TURVO_API_KEY", "9VjKgnIlQS1255cn7cRvJ6jNf8Z4MElP1PGgBTsH")
-	os.Setenv("TURVO_CLIENT_ID", "publicapi")
-	os.Setenv("TURVO_CLIENT_SECRET", "secret")
-	os.Setenv("TURVO_USERNAME", "axle@wickerparklogistics.com")
-	os.Setenv("TURVO_PASSWORD", "DZJ@pcu_qzd8ecz0fgw")
+func (suite *TurvoAPITestSuite) SetupTest() {
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.turvo.AddLocation(locations.Location{Name: "test"})
+	for i := 0; i < 40; i++ {
+		suite.turvo.AddShipment(model.Shipment{
+			CustomID: fmt.Sprintf("SEED-%d", i),
+			Status:   model.Status{Code: model.StatusCode{Key: "2101", Value: "Tendered"}},
+		})
+	}
 
 	suite.gw = NewTurvoAPIGateway()
-	if suite.gw == nil {
-		// Failed to create gateway instance
-		fmt.Println("ERROR CREATING GATEWAY")
-	}
+	suite.Require().NotNil(suite.gw, "gateway requires TURVO_* environment variables")
 }
 
 func (suite *TurvoAPITestSuite) TestRetrieveLoads_Success() {
-	loads, err := suite.gw.RetrieveLoads("21", "10")
+	details, err := suite.gw.RetrieveLoads(context.Background(), model.LoadQuery{Start: 21, PageSize: 10})
 	suite.Require().NoError(err)
-	suite.NotEmpty(loads)
-	suite.T().Logf("Retrieved loads: %+v", loads)
+	suite.Len(details.Shipments, 10)
+	suite.True(details.Pagination.MoreAvailable)
+
+	reqs := suite.turvo.RequestsTo("GET", "/shipments/list")
+	suite.Require().Len(reqs, 1)
+	suite.Equal("21", reqs[0].Query.Get("start"))
+	suite.Equal("10", reqs[0].Query.Get("pageSize"))
+}
+
+func (suite *TurvoAPITestSuite) TestGetLoad_Success() {
+	id := suite.turvo.AddShipment(model.ShipmentDetail{
+		CustomID: "DETAIL-1",
+		Status:   model.AvroStatus{Code: model.ValueKey{Key: "2102", Value: "Covered"}},
+		GlobalRoute: []model.RouteStop{
+			{ID: 7, GlobalRoute: model.GlobalRoute{Name: "Dock A", StopType: model.ValueKey{Key: "1500", Value: "Pickup"}}},
+		},
+	})
+
+	shipment, err := suite.gw.GetLoad(context.Background(), id)
+	suite.Require().NoError(err)
+	suite.Equal(id, shipment.ID)
+	suite.Equal("DETAIL-1", shipment.CustomID)
+	suite.Require().Len(shipment.GlobalRoute, 1)
+	suite.Equal(7, shipment.GlobalRoute[0].ID)
+	suite.Equal("Dock A", shipment.GlobalRoute[0].Name)
+}
+
+func (suite *TurvoAPITestSuite) TestGetLoad_NotFound() {
+	_, err := suite.gw.GetLoad(context.Background(), 999999)
+	suite.ErrorIs(err, ErrNotFound)
 }
 
 func (suite *TurvoAPITestSuite) TestCreateLoad_Success() {
 	// Prepare a valid CreateLoadRequest
-	loadReq := []model.CreateLoadRequest{
-		{
-			Pickup: model.CLPickup{
-				Name:     "test",
-				ApptTime: "2023-10-01T08:00:00Z",
-				City:     "Chicago",
-				State:    "IL",
-				Country:  "USA",
-			},
-			Consignee: model.CLConsignee{
-				Name:     "test",
-				ApptTime: "2023-10-01T17:00:00Z",
-				City:     "Los Angeles",
-				State:    "CA",
-				Country:  "USA",
-			},
-			Status: "Covered",
-			Customer: model.CLCustomer{
-				Name:          "Test Customer",
-				ExternalTMSId: "973069",
-			},
-			Specifications: model.CLSpecifications{
-				MinTempFahrenheit: 32,
-				MaxTempFahrenheit: 75,
-			},
-			TotalWeight: 10000,
+	loadReq := model.CreateLoadRequest{
+		Pickup: model.CLPickup{
+			Name:     "test",
+			ApptTime: "2023-10-01T08:00:00Z",
+			City:     "Chicago",
+			State:    "IL",
+			Country:  "USA",
+		},
+		Consignee: model.CLConsignee{
+			Name:     "test",
+			ApptTime: "2023-10-01T17:00:00Z",
+			City:     "Los Angeles",
+			State:    "CA",
+			Country:  "USA",
+		},
+		Status: "Covered",
+		Customer: model.CLCustomer{
+			Name:          "Test Customer",
+			ExternalTMSId: "973069",
 		},
+		Specifications: model.CLSpecifications{
+			MinTempFahrenheit: 32,
+			MaxTempFahrenheit: 75,
+		},
+		TotalWeight: 10000,
+	}
+	created, err := suite.gw.CreateLoad(context.Background(), loadReq, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.Require().NoError(err)
+	suite.Contains(suite.turvo.ShipmentIDs(), created.ID)
+	suite.Equal(fmt.Sprintf("FAKE-%d", created.ID), created.CustomID)
+	suite.Len(created.GlobalRoute, 2)
+
+	reqs := suite.turvo.RequestsTo("POST", "/shipments")
+	suite.Require().Len(reqs, 1)
+	var sent model.AvroLoadRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &sent))
+	suite.Equal(21, sent.GlobalRoute[0].Location.ID)
+	suite.Equal("Chicago, IL", sent.Lane.Start)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_MultiStop() {
+	load := validLoadRequest()
+	load.Stops = []model.CLStop{
+		{Type: "pickup", Name: "A", City: "Chicago", State: "IL", ApptTime: "2025-08-01T08:00:00-05:00"},
+		{Type: "pickup", Name: "B", City: "Gary", State: "IN", ApptTime: "2025-08-01T12:00:00-05:00"},
+		{Type: "delivery", Name: "C", City: "Dallas", State: "TX", ApptTime: "2025-08-02T09:00:00-05:00", ApptNote: "dock 2"},
+	}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{31, 32, 33}, CustomerID: 973069})
+	suite.Require().NoError(err)
+
+	ids := suite.turvo.ShipmentIDs()
+	doc, ok := suite.turvo.Shipment(ids[len(ids)-1])
+	suite.Require().True(ok)
+	var shipment model.ShipmentDetail
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+
+	suite.Require().Len(shipment.GlobalRoute, 3)
+	for i, want := range []struct {
+		stopType string
+		location int
+	}{{"1500", 31}, {"1500", 32}, {"1501", 33}} {
+		stop := shipment.GlobalRoute[i]
+		suite.Equal(i, stop.Sequence)
+		suite.Equal(want.stopType, stop.StopType.Key)
+		suite.Equal(want.location, stop.Location.ID)
+	}
+	suite.Equal("dock 2", shipment.GlobalRoute[2].Notes)
+	suite.Equal(model.Lane{Start: "Chicago, IL", End: "Dallas, TX"}, shipment.Lane)
+	suite.Equal(model.DateTime{Date: "2025-08-01T08:00:00-05:00", TimeZone: "America/Chicago"}, shipment.StartDate)
+	suite.Equal(model.DateTime{Date: "2025-08-02T09:00:00-05:00", TimeZone: "America/Chicago"}, shipment.EndDate)
+
+	item := shipment.CustomerOrder[0].Items[0]
+	suite.Equal([]model.ItemLocation{{GlobalShipLocationSourceID: "1", Name: "A"}, {GlobalShipLocationSourceID: "2", Name: "B"}}, item.PickupLocation)
+	suite.Equal([]model.ItemLocation{{GlobalShipLocationSourceID: "3", Name: "C"}}, item.DeliveryLocation)
+
+	detail := LoadFromShipment(shipment)
+	suite.Len(detail.Stops, 3)
+	suite.Equal("A", detail.Pickup.Name)
+	suite.Equal("C", detail.Consignee.Name)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_StopTimeZones() {
+	load := validLoadRequest()
+	load.Pickup = model.CLPickup{Name: "A", City: "El Paso", State: "TX", Zipcode: "79901", ReadyTime: "2025-08-01T06:00", ApptTime: "2025-08-01T09:00"}
+	load.Consignee = model.CLConsignee{Name: "B", State: "Georgia", ApptTime: "2025-08-02T19:00:00Z", MustDeliver: "2025-08-02"}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.Require().NoError(err)
+
+	reqs := suite.turvo.RequestsTo("POST", "/shipments")
+	suite.Require().Len(reqs, 1)
+	var body model.AvroLoadRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &body))
+
+	suite.Equal(model.DateTime{Date: "2025-08-01T09:00:00-06:00", TimeZone: "America/Denver"}, body.StartDate)
+	suite.Equal(model.DateTime{Date: "2025-08-02T15:00:00-04:00", TimeZone: "America/New_York"}, body.EndDate)
+
+	pickup, delivery := body.GlobalRoute[0], body.GlobalRoute[1]
+	suite.Equal("America/Denver", pickup.Timezone)
+	suite.Equal(model.Appointment{Date: "2025-08-01T06:00:00-06:00", Timezone: "America/Denver", Flex: 10800, HasTime: true}, pickup.Appointment)
+	suite.Equal("2025-08-01T06:00:00-06:00", pickup.PlannedAppointmentDate.Appointment.From.Date)
+	suite.Equal("2025-08-01T09:00:00-06:00", pickup.PlannedAppointmentDate.Appointment.To.Date)
+	suite.Equal(lookup.Default().Scheduling.FCFS, pickup.SchedulingType)
+	suite.Equal(lookup.Default().Scheduling.FCFS, pickup.PlannedAppointmentDate.SchedulingType)
+	suite.Equal("America/New_York", delivery.Appointment.Timezone)
+	suite.Equal("2025-08-02T23:59:59-04:00", delivery.PlannedAppointmentDate.Appointment.To.Date)
+
+	var shipment model.ShipmentDetail
+	doc, _ := suite.turvo.Shipment(suite.turvo.ShipmentIDs()[len(suite.turvo.ShipmentIDs())-1])
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+	detail := LoadFromShipment(shipment)
+	suite.Equal("2025-08-01T06:00:00-06:00", detail.Pickup.ReadyTime)
+	suite.Equal("2025-08-02T23:59:59-04:00", detail.Consignee.MustDeliver)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_TenantSchedulingKeys() {
+	keys := lookup.Default()
+	keys.Scheduling.FCFS = model.ValueKey{Key: "9700", Value: "FCFS"}
+	lookup.Use(keys)
+	suite.T().Cleanup(func() { lookup.Use(lookup.Default()) })
+
+	load := validLoadRequest()
+	load.Pickup.ReadyTime = "2023-10-01T06:00:00Z"
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.Require().NoError(err)
+
+	var shipment model.ShipmentDetail
+	doc, _ := suite.turvo.Shipment(suite.turvo.ShipmentIDs()[len(suite.turvo.ShipmentIDs())-1])
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+	suite.Equal("9700", shipment.GlobalRoute[0].SchedulingType.Key)
+	suite.NotEmpty(LoadFromShipment(shipment).Pickup.ReadyTime, "the tenant key is read back as a window")
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_InvalidStopTimes() {
+	load := validLoadRequest()
+	load.Pickup.Timezone = "Central"
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.ErrorIs(err, ErrValidation)
+	suite.ErrorContains(err, "stop 1: timezone is not a known IANA timezone")
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_ByAppointment() {
+	load := validLoadRequest()
+	load.Pickup = model.CLPickup{Name: "A", State: "IL", BusinessHours: "Mon-Fri 07:00-15:00", ApptTime: "2025-08-01T09:00"}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.Require().NoError(err)
+
+	reqs := suite.turvo.RequestsTo("POST", "/shipments")
+	suite.Require().Len(reqs, 1)
+	var body model.AvroLoadRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &body))
+	pickup := body.GlobalRoute[0]
+	suite.Equal(lookup.Default().Scheduling.Appointment, pickup.SchedulingType)
+	suite.Equal(model.Appointment{Date: "2025-08-01T09:00:00-05:00", Timezone: "America/Chicago", HasTime: true}, pickup.Appointment)
+	suite.Equal(pickup.Appointment.Date, pickup.PlannedAppointmentDate.Appointment.From.Date)
+	suite.Equal(pickup.Appointment.Date, pickup.PlannedAppointmentDate.Appointment.To.Date)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_OutsideBusinessHours() {
+	load := validLoadRequest()
+	load.Pickup = model.CLPickup{Name: "A", State: "IL", BusinessHours: "Mon-Fri 07:00-15:00", ApptTime: "2025-08-02T09:00"}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.ErrorIs(err, ErrValidation)
+	suite.ErrorContains(err, `stop 1: apptTime 2025-08-02T09:00:00-05:00 is outside businessHours "Mon-Fri 07:00-15:00"`)
+	suite.Empty(suite.turvo.RequestsTo("POST", "/shipments"))
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_CarrierOrder() {
+	load := validLoadRequest()
+	load.Carrier = model.CLCarrier{
+		Name:             "Fast Freight",
+		FirstDriverName:  "Ana",
+		FirstDriverPhone: "555-0100",
+	}
+	load.RateData.CarrierLhRateUsd = 1200
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069, CarrierID: 834187, CarrierName: "Fast Freight LLC"})
+	suite.Require().NoError(err)
+
+	ids := suite.turvo.ShipmentIDs()
+	doc, _ := suite.turvo.Shipment(ids[len(ids)-1])
+	var shipment model.ShipmentDetail
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+
+	suite.Require().Len(shipment.CarrierOrder, 1)
+	order := shipment.CarrierOrder[0]
+	suite.Equal(model.CarrierAvro{ID: 834187, Name: "Fast Freight LLC"}, order.Carrier)
+	suite.Require().Len(order.Drivers, 1)
+	suite.Equal("Ana", order.Drivers[0].Name)
+	suite.Equal(1200.0, order.Costs.TotalAmount)
+	suite.Require().Len(order.Costs.LineItem, 1)
+	suite.Equal("1600", order.Costs.LineItem[0].Code.Key)
+	for _, stop := range shipment.GlobalRoute {
+		suite.Equal([]model.GlobalRouteCarrierOrder{{CarrierID: 834187, CarrierOrderSourceID: 834187}}, stop.CarrierOrder)
 	}
-	err := suite.gw.CreateLoad(loadReq, 21, 10)
+
+	detail := LoadFromShipment(shipment)
+	suite.Equal("834187", detail.Carrier.ExternalTMSId)
+	suite.Equal("Ana", detail.Carrier.FirstDriverName)
+	suite.Equal(1200.0, detail.RateData.CarrierLhRateUsd)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_Accessorials() {
+	load := validLoadRequest()
+	load.Specifications = model.CLSpecifications{LiftgateDelivery: true, InsidePickup: true, Permits: true}
+	load.RateData = model.CLRateData{CustomerRateType: rating.TypeFlat, CustomerLhRateUsd: 2000, Accessorials: map[string]float64{"liftgateDelivery": 75, "permits": 120}}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.Require().NoError(err)
+
+	ids := suite.turvo.ShipmentIDs()
+	doc, _ := suite.turvo.Shipment(ids[len(ids)-1])
+	var shipment model.ShipmentDetail
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+
+	suite.Require().Len(shipment.GlobalRoute, 2)
+	suite.Equal([]model.ValueKey{{Key: "3101", Value: "Inside service"}, {Key: "3104", Value: "Permits"}}, shipment.GlobalRoute[0].Services)
+	suite.Equal([]model.ValueKey{{Key: "3100", Value: "Liftgate"}}, shipment.GlobalRoute[1].Services)
+
+	costs := shipment.CustomerOrder[0].Costs
+	suite.Equal(2195.0, costs.TotalAmount)
+	suite.Require().Len(costs.LineItem, 3)
+	suite.Equal("1617", costs.LineItem[1].Code.Key)
+	suite.Equal("1621", costs.LineItem[2].Code.Key)
+
+	detail := LoadFromShipment(shipment)
+	suite.Equal(load.Specifications, detail.Specifications)
+	suite.Equal(load.RateData, detail.RateData)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_HourlyCarrierRate() {
+	load := validLoadRequest()
+	load.RateData.CarrierRateType, load.RateData.CarrierLhRateUsd, load.RateData.CarrierNumHours = "hourly", 50, 10
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069, CarrierID: 834187})
+	suite.Require().NoError(err)
+
+	reqs := suite.turvo.RequestsTo("POST", "/shipments")
+	suite.Require().Len(reqs, 1)
+	var body model.AvroLoadRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &body))
+	costs := body.CarrierOrder[0].Costs
+	suite.Equal(500.0, costs.TotalAmount)
+	suite.Require().Len(costs.LineItem, 1)
+	suite.Equal(lookup.Current().LineItems.FreightHourly.Key, costs.LineItem[0].Code.Key)
+	suite.Equal(10, costs.LineItem[0].Qty)
+	suite.Equal(50.0, costs.LineItem[0].Price)
+
+	var shipment model.ShipmentDetail
+	shipment.CarrierOrder = []model.CarrierOrderDetail{{CarrierOrderAvro: body.CarrierOrder[0]}}
+	detail := LoadFromShipment(shipment)
+	suite.Equal(rating.TypeHourly, detail.RateData.CarrierRateType)
+	suite.Equal(50.0, detail.RateData.CarrierLhRateUsd)
+	suite.Equal(10.0, detail.RateData.CarrierNumHours)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_PerMileRate() {
+	load := validLoadRequest()
+	load.RouteMiles = 812.4
+	load.RateData = model.CLRateData{CustomerRateType: "Per Mile", CustomerLhRateUsd: 2.5, FscPercent: 12}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
 	suite.Require().NoError(err)
+
+	reqs := suite.turvo.RequestsTo("POST", "/shipments")
+	suite.Require().Len(reqs, 1)
+	var body model.AvroLoadRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &body))
+	costs := body.CustomerOrder[0].Costs
+	suite.Require().Len(costs.LineItem, 2)
+	suite.Equal(lookup.Current().LineItems.FreightPerMile.Key, costs.LineItem[0].Code.Key)
+	suite.Equal(812, costs.LineItem[0].Qty)
+	suite.Equal(2031.0, costs.LineItem[0].Amount)
+	suite.Equal(lookup.Current().LineItems.FuelPercent.Key, costs.LineItem[1].Code.Key)
+	suite.Equal(243.72, costs.LineItem[1].Amount)
+	suite.Equal(2274.72, costs.TotalAmount)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_InvalidRate() {
+	load := validLoadRequest()
+	load.RateData = model.CLRateData{CustomerRateType: "hourly", CustomerLhRateUsd: 90}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.ErrorIs(err, ErrValidation)
+	suite.ErrorContains(err, "customerNumHours is required")
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_UnrequestedAccessorial() {
+	load := validLoadRequest()
+	load.RateData.Accessorials = map[string]float64{"labor": 50}
+	_, err := suite.gw.CreateLoad(context.Background(), load, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.ErrorIs(err, ErrValidation)
+	suite.Empty(suite.turvo.RequestsTo("POST", "/shipments"))
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_NoCarrier() {
+	_, err := suite.gw.CreateLoad(context.Background(), validLoadRequest(), model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.Require().NoError(err)
+
+	reqs := suite.turvo.RequestsTo("POST", "/shipments")
+	suite.Require().Len(reqs, 1)
+	var body model.AvroLoadRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &body))
+	suite.Empty(body.CarrierOrder)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_LocationCountMismatch() {
+	_, err := suite.gw.CreateLoad(context.Background(), validLoadRequest(), model.LoadRefs{LocationIDs: []int{1}, CustomerID: 973069})
+	suite.ErrorIs(err, ErrValidation)
+	suite.Empty(suite.turvo.RequestsTo("POST", "/shipments"))
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLoad_UpstreamFailure() {
+	suite.turvo.Inject(faketurvo.Failure{Method: "POST", Path: "/shipments", Status: 400, Body: `{"Status":"ERROR"}`})
+	_, err := suite.gw.CreateLoad(context.Background(), validLoadRequest(), model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
+	suite.ErrorContains(err, "status code: 400")
 }
 
 func (suite *TurvoAPITestSuite) TestRetrieveLocations_Success() {
-	locations, err := suite.gw.RetrieveLocations("test")
+	found, err := suite.gw.RetrieveLocations(context.Background(), "test")
 	suite.Require().NoError(err)
-	suite.NotEmpty(locations)
-	suite.T().Logf("Retrieved locations: %+v", locations)
+	suite.Require().Len(found, 1)
+	suite.Equal("test", found[0].Name)
 }
 
-func (suite *TurvoAPITestSuite) TestRetrieveLoads_InvalidToken() {
-	origToken := suite.gw.Token
+func (suite *TurvoAPITestSuite) TestCreateLocation_Success() {
+	created, err := suite.gw.CreateLocation(context.Background(), locations.CreateRequest{
+		Name:      "New Dock",
+		Addresses: []locations.Address{{Line1: "5 Harbor Rd", City: "Houston", State: "TX", Zip: "77001"}},
+	})
+	suite.Require().NoError(err)
+	suite.NotZero(created.ID)
+	suite.Equal("New Dock", created.Name)
+
+	found, err := suite.gw.RetrieveLocations(context.Background(), "New Dock")
+	suite.Require().NoError(err)
+	suite.Require().Len(found, 1)
+	suite.Equal(created.ID, found[0].ID)
+}
+
+func (suite *TurvoAPITestSuite) TestCreateLocation_Rejected() {
+	_, err := suite.gw.CreateLocation(context.Background(), locations.CreateRequest{Name: "No Address"})
+	suite.ErrorIs(err, ErrValidation)
+}
+
+func (suite *TurvoAPITestSuite) TestRetrieveCustomers_Success() {
+	suite.turvo.AddCustomer(customer.Customer{Name: "Bunker"})
+	suite.turvo.AddCustomer(customer.Customer{Name: "37th St Bakery"})
+
+	found, err := suite.gw.RetrieveCustomers(context.Background(), customer.Query{Name: "Bunker"})
+	suite.Require().NoError(err)
+	suite.Require().Len(found, 1)
+	suite.Equal("Bunker", found[0].Name)
+}
+
+func (suite *TurvoAPITestSuite) TestRetrieveCarriers_Success() {
+	suite.turvo.AddCarrier(carrier.Carrier{Name: "Fast Freight", DotNumber: "123456", McNumber: "MC-1"})
+	suite.turvo.AddCarrier(carrier.Carrier{Name: "Slow Freight", DotNumber: "654321", McNumber: "MC-2"})
+
+	found, err := suite.gw.RetrieveCarriers(context.Background(), carrier.Query{DotNumber: "123456"})
+	suite.Require().NoError(err)
+	suite.Require().Len(found, 1)
+	suite.Equal("Fast Freight", found[0].Name)
+
+	reqs := suite.turvo.RequestsTo("GET", "/carriers/list")
+	suite.Require().Len(reqs, 1)
+	suite.Equal(url.Values{"dotNumber[eq]": {"123456"}}, reqs[0].Query)
+}
+
+func (suite *TurvoAPITestSuite) TestRetrieveLoads_InvalidTokenIsRefreshed() {
 	suite.gw.Token = "invalid_token"
-	_, err := suite.gw.RetrieveLoads("21", "10")
-	suite.Error(err)
-	suite.gw.Token = origToken // restore
+	_, err := suite.gw.RetrieveLoads(context.Background(), model.LoadQuery{Start: 21, PageSize: 10})
+	suite.NoError(err)
+	suite.NotEqual("invalid_token", suite.gw.Token)
+	suite.Equal(2, suite.turvo.TokenRequests())
 }
 
 func (suite *TurvoAPITestSuite) TestCreateLoad_InvalidRequest() {
 	// Missing required fields
-	loadReq := []model.CreateLoadRequest{
-		{
-			Pickup:      model.CLPickup{},
-			Consignee:   model.CLConsignee{},
-			Status:      "",
-			TotalWeight: 0,
-		},
+	loadReq := model.CreateLoadRequest{
+		Pickup:      model.CLPickup{},
+		Consignee:   model.CLConsignee{},
+		Status:      "",
+		TotalWeight: 0,
 	}
-	err := suite.gw.CreateLoad(loadReq, 21, 10)
+	_, err := suite.gw.CreateLoad(context.Background(), loadReq, model.LoadRefs{LocationIDs: []int{21, 10}, CustomerID: 973069})
 	suite.Error(err)
 }
+
+// validLoadRequest is the smallest request the transformer accepts.
+func validLoadRequest() model.CreateLoadRequest {
+	return model.CreateLoadRequest{
+		Pickup:    model.CLPickup{Name: "test", ApptTime: "2023-10-01T08:00:00Z"},
+		Consignee: model.CLConsignee{Name: "test", ApptTime: "2023-10-01T17:00:00Z"},
+		Status:    "Covered",
+	}
+}
+
 func TestTurvoAPITestSuite(t *testing.T) {
 	suite.Run(t, new(TurvoAPITestSuite))
 }
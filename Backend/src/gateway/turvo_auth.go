@@ -0,0 +1,176 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultRefreshSkew is how long before expiry a token is considered stale.
+const defaultRefreshSkew = time.Minute
+
+type AuthRequestBody struct {
+	GrantType string `json:"grant_type"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	Scope     string `json:"scope"`
+	Type      string `json:"type"`
+}
+
+type AuthResponseBody struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// getAuthToken is a method so it can use values stored on the gateway instance.
+func (r *TurvoAPIGateway) getAuthToken(ctx context.Context) (AuthResponseBody, error) {
+	tokenURL := r.Host + "/oauth/token"
+	requestBody, err := json.Marshal(AuthRequestBody{
+		GrantType: "password",
+		Username:  r.Username,
+		Password:  r.Password,
+		Scope:     "read+trust+write",
+		Type:      "business",
+	})
+	if err != nil {
+		return AuthResponseBody{}, fmt.Errorf("error creating request body JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return AuthResponseBody{}, fmt.Errorf("error creating HTTP request: %w", err)
+	}
+
+	req.Header.Set("x-api-key", r.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	q := req.URL.Query()
+	q.Add("client_id", r.ClientID)
+	q.Add("client_secret", r.ClientSecret)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return AuthResponseBody{}, fmt.Errorf("error sending token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return AuthResponseBody{}, fmt.Errorf("error reading token response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		authErr := statusError("request token", resp.StatusCode, string(body), nil)
+		if authErr.Kind != KindRateLimited && authErr.Kind != KindUnavailable {
+			authErr.Kind = KindAuth
+		}
+		return AuthResponseBody{}, authErr
+	}
+
+	var tokenResponse AuthResponseBody
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return AuthResponseBody{}, fmt.Errorf("error parsing token response JSON: %w", err)
+	}
+
+	if tokenResponse.AccessToken == "" {
+		return AuthResponseBody{}, fmt.Errorf("access token was empty in the response")
+	}
+
+	return tokenResponse, nil
+}
+
+// accessToken returns the cached token, fetching a new one when there is none or
+// when it expires within RefreshSkew. The lock is held across the refresh so
+// concurrent callers wait for a single token request instead of racing.
+func (r *TurvoAPIGateway) accessToken(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Token != "" && (r.TokenExpiry.IsZero() || r.clock().Add(r.refreshSkew()).Before(r.TokenExpiry)) {
+		return r.Token, nil
+	}
+	return r.refreshTokenLocked(ctx)
+}
+
+// refreshTokenLocked requests a new token and records its expiry. r.mu must be held.
+func (r *TurvoAPIGateway) refreshTokenLocked(ctx context.Context) (string, error) {
+	tokenResponse, err := r.getAuthToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	r.Token = tokenResponse.AccessToken
+	r.TokenExpiry = time.Time{}
+	if tokenResponse.ExpiresIn > 0 {
+		r.TokenExpiry = r.clock().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+	return r.Token, nil
+}
+
+// invalidateToken drops the cached token if it is still the one that was rejected.
+// Another goroutine may already have replaced it, in which case nothing changes.
+func (r *TurvoAPIGateway) invalidateToken(rejected string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Token == rejected {
+		r.Token = ""
+		r.TokenExpiry = time.Time{}
+	}
+}
+
+// do sends an authenticated request to Turvo. If Turvo answers 401 the token is
+// refreshed and the request is replayed once with the new token. The token
+// request shares req's context, so it is cancelled together with the call.
+func (r *TurvoAPIGateway) do(req *http.Request) (*http.Response, error) {
+	token, err := r.accessToken(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("error authenticating with Turvo: %w", err)
+	}
+
+	resp, err := r.send(req, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	r.invalidateToken(token)
+	token, err = r.accessToken(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("error re-authenticating with Turvo: %w", err)
+	}
+
+	replay, err := rewind(req, 1)
+	if err != nil {
+		return nil, err
+	}
+	return r.send(replay, token)
+}
+
+func (r *TurvoAPIGateway) send(req *http.Request, token string) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("x-api-key", r.APIKey)
+	return r.Client.Do(req)
+}
+
+func (r *TurvoAPIGateway) refreshSkew() time.Duration {
+	if r.RefreshSkew > 0 {
+		return r.RefreshSkew
+	}
+	return defaultRefreshSkew
+}
+
+func (r *TurvoAPIGateway) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
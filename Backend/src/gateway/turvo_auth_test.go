@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// stubTurvo answers token requests with sequential tokens and accepts only the
+// most recently issued one on every other path.
+type stubTurvo struct {
+	mu         sync.Mutex
+	expiresIn  int
+	issued     int
+	tokenCalls int
+	calls      []string
+}
+
+func (s *stubTurvo) Do(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.HasSuffix(req.URL.Path, "/oauth/token") {
+		s.tokenCalls++
+		s.issued++
+		body := fmt.Sprintf(`{"access_token":"token-%d","expires_in":%d}`, s.issued, s.expiresIn)
+		return stubResponse(http.StatusOK, body), nil
+	}
+
+	auth := req.Header.Get("Authorization")
+	s.calls = append(s.calls, auth)
+	if auth != fmt.Sprintf("Bearer token-%d", s.issued) {
+		return stubResponse(http.StatusUnauthorized, `{"error":"invalid_token"}`), nil
+	}
+	if req.Body != nil {
+		payload, _ := io.ReadAll(req.Body)
+		return stubResponse(http.StatusOK, string(payload)), nil
+	}
+	return stubResponse(http.StatusOK, `{}`), nil
+}
+
+func stubResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+type TurvoAuthTestSuite struct {
+	suite.Suite
+	stub  *stubTurvo
+	gw    *TurvoAPIGateway
+	clock time.Time
+}
+
+func (suite *TurvoAuthTestSuite) SetupTest() {
+	suite.stub = &stubTurvo{expiresIn: 3600}
+	suite.clock = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	suite.gw = &TurvoAPIGateway{
+		Host:   "https://turvo.test/v1",
+		Client: suite.stub,
+		now:    func() time.Time { return suite.clock },
+	}
+}
+
+func (suite *TurvoAuthTestSuite) TestAccessToken_TracksExpiry() {
+	token, err := suite.gw.accessToken(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal("token-1", token)
+	suite.Equal(suite.clock.Add(time.Hour), suite.gw.TokenExpiry)
+
+	token, err = suite.gw.accessToken(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal("token-1", token)
+	suite.Equal(1, suite.stub.tokenCalls)
+}
+
+func (suite *TurvoAuthTestSuite) TestAccessToken_RefreshesBeforeExpiry() {
+	_, err := suite.gw.accessToken(context.Background())
+	suite.Require().NoError(err)
+
+	suite.clock = suite.clock.Add(time.Hour - 30*time.Second)
+	token, err := suite.gw.accessToken(context.Background())
+	suite.Require().NoError(err)
+	suite.Equal("token-2", token)
+	suite.Equal(2, suite.stub.tokenCalls)
+}
+
+func (suite *TurvoAuthTestSuite) TestDo_ReplaysOnceAfterUnauthorized() {
+	suite.gw.Token = "revoked"
+	suite.gw.TokenExpiry = suite.clock.Add(time.Hour)
+
+	req, err := http.NewRequest("POST", suite.gw.Host+"/shipments", strings.NewReader(`{"id":1}`))
+	suite.Require().NoError(err)
+
+	resp, err := suite.gw.do(req)
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	suite.Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal(`{"id":1}`, string(body))
+	suite.Equal([]string{"Bearer revoked", "Bearer token-1"}, suite.stub.calls)
+}
+
+func (suite *TurvoAuthTestSuite) TestDo_ConcurrentCallersShareOneRefresh() {
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", suite.gw.Host+"/shipments/list", nil)
+			resp, err := suite.gw.do(req)
+			if suite.NoError(err) {
+				resp.Body.Close()
+				suite.Equal(http.StatusOK, resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+	suite.Equal(1, suite.stub.tokenCalls)
+}
+
+func TestTurvoAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(TurvoAuthTestSuite))
+}
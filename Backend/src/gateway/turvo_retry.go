@@ -0,0 +1,245 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed Turvo calls are retried.
+type RetryPolicy struct {
+	// MaxAttempts caps the number of tries, including the first one.
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt; it doubles every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff computed from BaseDelay.
+	MaxDelay time.Duration
+	// MaxElapsed caps the total time from the start of the first attempt,
+	// counting both the attempts and the waits between them.
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy is used when TurvoAPIGateway.Retry is the zero value.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    4 * time.Second,
+	MaxElapsed:  15 * time.Second,
+}
+
+// Attempt is the outcome of a single try made by the request executor.
+type Attempt struct {
+	StatusCode int
+	Err        error
+	// Wait is the delay applied after this attempt before the next one.
+	Wait time.Duration
+}
+
+func (a Attempt) String() string {
+	if a.Err != nil {
+		return a.Err.Error()
+	}
+	return fmt.Sprintf("status %d", a.StatusCode)
+}
+
+// RetryError is returned when a request that was retried at least once does
+// not end in success, whether the policy ran out or the last attempt failed in
+// a way that is not retried. It keeps every attempt so callers can see what
+// happened.
+type RetryError struct {
+	Method   string
+	URL      string
+	Attempts []Attempt
+	// Body is the response body of the last attempt, if it got a response.
+	Body string
+}
+
+func (e *RetryError) Error() string {
+	history := make([]string, len(e.Attempts))
+	for i, a := range e.Attempts {
+		history[i] = fmt.Sprintf("#%d %s", i+1, a)
+	}
+	msg := fmt.Sprintf("%s %s failed after %d attempt(s) [%s]", e.Method, e.URL, len(e.Attempts), strings.Join(history, "; "))
+	if e.Body != "" {
+		msg += ", response: " + e.Body
+	}
+	return msg
+}
+
+// Unwrap exposes the transport error of the last attempt, if any.
+func (e *RetryError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// StatusCode is the HTTP status of the last attempt, or 0 if it never got a response.
+func (e *RetryError) StatusCode() int {
+	if len(e.Attempts) == 0 {
+		return 0
+	}
+	return e.Attempts[len(e.Attempts)-1].StatusCode
+}
+
+// execute sends req through do, retrying on 429, 5xx and transport errors.
+// Non-idempotent requests are only retried on 429, because Turvo rejects those
+// before doing any work. A first response that is not retried is returned
+// as-is for the caller to inspect, as is a success after retries; any other
+// outcome once the request has been retried is a *RetryError. Retries stop as soon as req's context is done or its deadline would pass
+// before the next attempt.
+func (r *TurvoAPIGateway) execute(req *http.Request, idempotent bool) (*http.Response, error) {
+	ctx := req.Context()
+	policy := r.retryPolicy()
+	start := r.clock()
+	var attempts []Attempt
+
+	for {
+		attemptReq, err := rewind(req, len(attempts))
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := r.do(attemptReq)
+		attempt := Attempt{Err: err}
+		if resp != nil {
+			attempt.StatusCode = resp.StatusCode
+		}
+		if ctx.Err() != nil || !shouldRetry(resp, err, idempotent) {
+			if len(attempts) == 0 || err == nil && resp.StatusCode < 400 {
+				return resp, err
+			}
+			return nil, &RetryError{Method: req.Method, URL: req.URL.Redacted(), Attempts: append(attempts, attempt), Body: drain(resp)}
+		}
+
+		wait := backoff(policy, len(attempts))
+		if after, ok := retryAfter(resp, r.clock()); ok {
+			wait = after
+		}
+
+		body := drain(resp)
+
+		if len(attempts)+1 >= policy.MaxAttempts || r.clock().Sub(start)+wait > policy.MaxElapsed || r.pastDeadline(ctx, wait) {
+			attempts = append(attempts, attempt)
+			return nil, &RetryError{Method: req.Method, URL: req.URL.Redacted(), Attempts: attempts, Body: body}
+		}
+
+		attempt.Wait = wait
+		attempts = append(attempts, attempt)
+		if err := r.pause(ctx, wait); err != nil {
+			return nil, &RetryError{Method: req.Method, URL: req.URL.Redacted(), Attempts: append(attempts, Attempt{Err: err})}
+		}
+	}
+}
+
+// drain reads and closes the body of resp, if any.
+func drain(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	return string(raw)
+}
+
+// rewind returns req for the first attempt and a copy with a fresh body afterwards.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 {
+		return req, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("error rewinding request body: %w", err)
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+func shouldRetry(resp *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		return idempotent
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return idempotent && resp.StatusCode >= 500
+}
+
+// backoff returns the jittered exponential delay after the given number of
+// completed retries: a random value between half and all of BaseDelay*2^n.
+func backoff(policy RetryPolicy, retries int) time.Duration {
+	d := policy.BaseDelay << uint(retries)
+	if d <= 0 || d > policy.MaxDelay {
+		d = policy.MaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (r *TurvoAPIGateway) retryPolicy() RetryPolicy {
+	policy := r.Retry
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if policy.MaxElapsed <= 0 {
+		policy.MaxElapsed = DefaultRetryPolicy.MaxElapsed
+	}
+	return policy
+}
+
+// pastDeadline reports whether waiting d would outlive ctx's deadline.
+func (r *TurvoAPIGateway) pastDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && r.clock().Add(d).After(deadline)
+}
+
+// pause waits for d or until ctx is done, whichever comes first.
+func (r *TurvoAPIGateway) pause(ctx context.Context, d time.Duration) error {
+	if r.sleep != nil {
+		r.sleep(d)
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
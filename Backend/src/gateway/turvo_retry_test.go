@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/customer"
+	"drumkit.com/interview/src/test/faketurvo"
+	"github.com/stretchr/testify/suite"
+)
+
+type TurvoRetryTestSuite struct {
+	suite.Suite
+	turvo *faketurvo.Server
+	gw    *TurvoAPIGateway
+	waits []time.Duration
+}
+
+func (suite *TurvoRetryTestSuite) SetupTest() {
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.gw = NewTurvoAPIGateway()
+	suite.Require().NotNil(suite.gw)
+
+	suite.waits = nil
+	suite.gw.sleep = func(d time.Duration) { suite.waits = append(suite.waits, d) }
+	suite.gw.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, MaxElapsed: 10 * time.Second}
+}
+
+func (suite *TurvoRetryTestSuite) TestRetriesServerErrorsUntilSuccess() {
+	suite.turvo.Inject(faketurvo.Failure{Path: "/shipments/list", Status: http.StatusBadGateway, Times: 2})
+
+	_, err := suite.gw.RetrieveLoads(context.Background(), model.LoadQuery{})
+	suite.Require().NoError(err)
+	suite.Len(suite.turvo.RequestsTo("GET", "/shipments/list"), 3)
+	suite.Require().Len(suite.waits, 2)
+	suite.InDelta(75*time.Millisecond, suite.waits[0], float64(25*time.Millisecond))
+	suite.InDelta(150*time.Millisecond, suite.waits[1], float64(50*time.Millisecond))
+}
+
+func (suite *TurvoRetryTestSuite) TestHonorsRetryAfter() {
+	suite.turvo.Inject(faketurvo.Failure{
+		Path:   "/locations/list",
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"2"}},
+		Times:  1,
+	})
+
+	_, err := suite.gw.RetrieveLocations(context.Background(), "test")
+	suite.Require().NoError(err)
+	suite.Equal([]time.Duration{2 * time.Second}, suite.waits)
+}
+
+func (suite *TurvoRetryTestSuite) TestGivesUpWithAttemptHistory() {
+	suite.turvo.Inject(faketurvo.Failure{Path: "/customers/list", Status: http.StatusServiceUnavailable, Body: `{"Status":"ERROR"}`})
+
+	_, err := suite.gw.RetrieveCustomers(context.Background(), customer.Query{Name: "Bunker"})
+	var retryErr *RetryError
+	suite.Require().True(errors.As(err, &retryErr), "expected *RetryError, got %v", err)
+	suite.Len(retryErr.Attempts, 3)
+	suite.Equal(http.StatusServiceUnavailable, retryErr.StatusCode())
+	suite.Contains(err.Error(), "#3 status 503")
+	suite.Contains(err.Error(), `{"Status":"ERROR"}`)
+}
+
+func (suite *TurvoRetryTestSuite) TestWrapsFailureAfterRetry() {
+	suite.turvo.Inject(faketurvo.Failure{Path: "/shipments/999", Status: http.StatusServiceUnavailable, Times: 2})
+
+	_, err := suite.gw.GetLoad(context.Background(), 999)
+	var retryErr *RetryError
+	suite.Require().ErrorAs(err, &retryErr)
+	suite.Len(retryErr.Attempts, 3)
+	suite.Equal(http.StatusNotFound, retryErr.StatusCode())
+	suite.Contains(err.Error(), "#1 status 503")
+	suite.ErrorIs(err, ErrNotFound)
+}
+
+func (suite *TurvoRetryTestSuite) TestDoesNotWrapFirstFailure() {
+	_, err := suite.gw.GetLoad(context.Background(), 999)
+	var retryErr *RetryError
+	suite.False(errors.As(err, &retryErr))
+	suite.ErrorIs(err, ErrNotFound)
+}
+
+func (suite *TurvoRetryTestSuite) TestStopsWhenElapsedBudgetIsSpent() {
+	suite.gw.Retry.MaxElapsed = time.Second
+	suite.turvo.Inject(faketurvo.Failure{
+		Path:   "/shipments/list",
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"5"}},
+	})
+
+	_, err := suite.gw.RetrieveLoads(context.Background(), model.LoadQuery{})
+	suite.Error(err)
+	suite.Empty(suite.waits)
+	suite.Len(suite.turvo.RequestsTo("GET", "/shipments/list"), 1)
+}
+
+func (suite *TurvoRetryTestSuite) TestElapsedBudgetCountsSlowAttempts() {
+	suite.gw.Retry = RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond, MaxElapsed: 100 * time.Millisecond}
+	suite.turvo.Inject(faketurvo.Failure{Path: "/shipments/list", Status: http.StatusBadGateway, Delay: 60 * time.Millisecond})
+
+	_, err := suite.gw.RetrieveLoads(context.Background(), model.LoadQuery{})
+	var retryErr *RetryError
+	suite.Require().ErrorAs(err, &retryErr)
+	suite.Len(retryErr.Attempts, 2, "the second slow attempt spends the budget")
+	suite.Len(suite.waits, 1)
+}
+
+func (suite *TurvoRetryTestSuite) TestStopsWhenBackoffOutlivesDeadline() {
+	suite.turvo.Inject(faketurvo.Failure{
+		Path:   "/shipments/list",
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": []string{"3"}},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := suite.gw.RetrieveLoads(ctx, model.LoadQuery{})
+	suite.Error(err)
+	suite.Empty(suite.waits)
+}
+
+func (suite *TurvoRetryTestSuite) TestCancelsInFlightCall() {
+	suite.turvo.Inject(faketurvo.Failure{Path: "/shipments/list", Delay: 500 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := suite.gw.RetrieveLoads(ctx, model.LoadQuery{})
+	suite.ErrorIs(err, context.DeadlineExceeded)
+	suite.Less(time.Since(started), 400*time.Millisecond)
+	suite.Empty(suite.waits)
+}
+
+func (suite *TurvoRetryTestSuite) TestRetriesNetworkErrors() {
+	// net/http silently retries a dropped idempotent request once on a reused
+	// connection, so drop twice to make sure the executor sees the error.
+	suite.turvo.Inject(faketurvo.Failure{Path: "/shipments/list", Drop: true, Times: 2})
+
+	_, err := suite.gw.RetrieveLoads(context.Background(), model.LoadQuery{})
+	suite.NoError(err)
+	suite.NotEmpty(suite.waits)
+}
+
+func (suite *TurvoRetryTestSuite) TestCreateLoadIsNotRetriedOnServerError() {
+	suite.turvo.Inject(faketurvo.Failure{Method: "POST", Path: "/shipments", Status: http.StatusInternalServerError, Times: 1})
+
+	_, err := suite.gw.CreateLoad(context.Background(), validLoadRequest(), model.LoadRefs{LocationIDs: []int{1, 2}, CustomerID: 973069})
+	suite.Error(err)
+	suite.Len(suite.turvo.RequestsTo("POST", "/shipments"), 1)
+	suite.Empty(suite.turvo.ShipmentIDs())
+}
+
+func (suite *TurvoRetryTestSuite) TestCreateLoadIsRetriedWhenRateLimited() {
+	suite.turvo.Inject(faketurvo.Failure{Method: "POST", Path: "/shipments", Status: http.StatusTooManyRequests, Times: 1})
+
+	_, err := suite.gw.CreateLoad(context.Background(), validLoadRequest(), model.LoadRefs{LocationIDs: []int{1, 2}, CustomerID: 973069})
+	suite.NoError(err)
+	suite.Len(suite.turvo.RequestsTo("POST", "/shipments"), 2)
+	suite.Len(suite.turvo.ShipmentIDs(), 1)
+}
+
+func TestTurvoRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(TurvoRetryTestSuite))
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/service"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type ChangeStatusHandler struct {
+	Service *service.LoadService
+}
+
+// ChangeStatusRequest is the body of the change-status endpoint.
+type ChangeStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// ChangeStatusHandlerLambda moves the load named by the {id} path parameter
+// to a new status. Any reason is sent to Turvo as the status note; canceling
+// a load or putting it on hold requires one.
+func (h *ChangeStatusHandler) ChangeStatusHandlerLambda(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
+
+	id, err := strconv.Atoi(request.PathParameters["id"])
+	if err != nil || id <= 0 {
+		return badRequest("id must be a positive integer"), nil
+	}
+
+	var body ChangeStatusRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+		return badRequest("Invalid request body: " + err.Error()), nil
+	}
+	status, ok := model.StatusCodeForValue(body.Status)
+	if !ok {
+		return badRequest("unknown status " + strconv.Quote(body.Status)), nil
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if status.RequiresReason() && reason == "" {
+		return badRequest("reason is required to move a load to " + status.Name()), nil
+	}
+
+	load, err := h.Service.ChangeStatus(ctx, id, model.StatusChange{Status: status, Reason: reason})
+	if err != nil {
+		return errorResponse(err), nil
+	}
+
+	return jsonResponse(200, load), nil
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+	"time"
+)
+
+// maxResponseReserve caps how much of the remaining invocation time is kept
+// back for building and returning a response after downstream calls give up.
+const maxResponseReserve = time.Second
+
+// invocationContext derives the context used for downstream calls from the
+// Lambda context. The Lambda deadline is pulled in by a tenth of the remaining
+// time (at most maxResponseReserve), so in-flight Turvo calls are cancelled
+// while there is still time to answer the caller.
+func invocationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	reserve := time.Until(deadline) / 10
+	if reserve > maxResponseReserve {
+		reserve = maxResponseReserve
+	}
+	return context.WithDeadline(ctx, deadline.Add(-reserve))
+}
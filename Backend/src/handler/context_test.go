@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvocationContext_ReservesTimeBeforeLambdaDeadline(t *testing.T) {
+	deadline := time.Now().Add(3 * time.Second)
+	lambdaCtx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	ctx, cancelCall := invocationContext(lambdaCtx)
+	defer cancelCall()
+
+	callDeadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, deadline.Add(-300*time.Millisecond), callDeadline, 20*time.Millisecond)
+}
+
+func TestInvocationContext_CapsReserve(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	lambdaCtx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	ctx, cancelCall := invocationContext(lambdaCtx)
+	defer cancelCall()
+
+	callDeadline, _ := ctx.Deadline()
+	assert.Equal(t, deadline.Add(-maxResponseReserve), callDeadline)
+}
+
+func TestInvocationContext_WithoutDeadline(t *testing.T) {
+	ctx, cancel := invocationContext(context.Background())
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+
+	cancel()
+	assert.Error(t, ctx.Err())
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
 
 	"drumkit.com/interview/src/model"
 	"drumkit.com/interview/src/service"
+	"drumkit.com/interview/src/validation"
 	"github.com/aws/aws-lambda-go/events"
 )
 
@@ -12,22 +15,23 @@ type CreateLoadsHandler struct {
 	Service *service.LoadService
 }
 
-func (h *CreateLoadsHandler) CreateLoadsHandlerLambda(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	// Here you would typically parse the request body to get the load data
-	// For simplicity, we assume the load data is valid and directly call the service
+func (h *CreateLoadsHandler) CreateLoadsHandlerLambda(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
+
 	var loads model.CreateLoadRequest
 	err := json.Unmarshal([]byte(request.Body), &loads)
 	if err != nil {
-		return events.APIGatewayProxyResponse{StatusCode: 400, Body: "Invalid request body"}, err
+		return badRequest("Invalid request body: " + err.Error()), nil
+	}
+	if err := validation.CreateLoad(loads); err != nil {
+		return errorResponse(err), nil
 	}
 
-	err = h.Service.CreateLoad(loads)
+	created, err := h.Service.CreateLoad(ctx, loads)
 	if err != nil {
-		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		return errorResponse(err), nil
 	}
 
-	return events.APIGatewayProxyResponse{
-		StatusCode: 200,
-		Body:       "Loads created successfully",
-	}, nil
+	return jsonResponse(http.StatusCreated, created), nil
 }
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/service"
+	logger "drumkit.com/interview/src/utils"
+	"drumkit.com/interview/src/validation"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// maxBatchSize caps the loads accepted in one batch request.
+const maxBatchSize = 100
+
+type CreateLoadsBatchHandler struct {
+	Service *service.LoadService
+}
+
+// BatchResult is the outcome for one load of a batch, at Index in the request.
+type BatchResult struct {
+	Index int `json:"index"`
+	// Status is the status the load would have got on its own: 201 when it
+	// was created, otherwise the status of its error.
+	Status int                `json:"status"`
+	Load   *model.CreatedLoad `json:"load,omitempty"`
+	Error  *ErrorDetail       `json:"error,omitempty"`
+}
+
+// BatchResponse is the body returned for a batch request.
+type BatchResponse struct {
+	Created int           `json:"created"`
+	Failed  int           `json:"failed"`
+	Results []BatchResult `json:"results"`
+}
+
+// CreateLoadsBatchHandlerLambda creates every load of a JSON array body and
+// returns one result per load. Invalid loads are reported without being sent
+// and do not stop the rest. The response is 200 when every load was created
+// and 207 otherwise.
+func (h *CreateLoadsBatchHandler) CreateLoadsBatchHandlerLambda(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
+
+	var loads []model.CreateLoadRequest
+	if err := json.Unmarshal([]byte(request.Body), &loads); err != nil {
+		return badRequest("Invalid request body: " + err.Error()), nil
+	}
+	if len(loads) == 0 {
+		return badRequest("the batch has no loads"), nil
+	}
+	if len(loads) > maxBatchSize {
+		return badRequest(fmt.Sprintf("a batch holds at most %d loads", maxBatchSize)), nil
+	}
+
+	resp := BatchResponse{Results: make([]BatchResult, len(loads))}
+	var valid []model.CreateLoadRequest
+	var validAt []int
+	for i, load := range loads {
+		resp.Results[i].Index = i
+		if err := validation.CreateLoad(load); err != nil {
+			resp.Results[i].fail(err)
+			continue
+		}
+		valid = append(valid, load)
+		validAt = append(validAt, i)
+	}
+
+	for j, result := range h.Service.CreateLoads(ctx, valid) {
+		r := &resp.Results[validAt[j]]
+		if result.Err != nil {
+			r.fail(result.Err)
+			continue
+		}
+		load := result.Load
+		r.Status, r.Load = http.StatusCreated, &load
+	}
+
+	for _, r := range resp.Results {
+		if r.Error != nil {
+			resp.Failed++
+		} else {
+			resp.Created++
+		}
+	}
+	status := http.StatusOK
+	if resp.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	return jsonResponse(status, resp), nil
+}
+
+func (r *BatchResult) fail(err error) {
+	status, detail := errorDetail(err)
+	r.Status, r.Error = status, &detail
+	if logger.Logger != nil {
+		logger.Logger.Error("batch load failed", zap.Int("index", r.Index), zap.Int("status", status), zap.Error(err))
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"drumkit.com/interview/src/service"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type GetLoadHandler struct {
+	Service *service.LoadService
+}
+
+// GetLoadHandlerLambda returns one load. The ID comes from the {id} path
+// parameter, or the id query parameter when the route has none.
+func (h *GetLoadHandler) GetLoadHandlerLambda(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
+
+	raw := request.PathParameters["id"]
+	if raw == "" {
+		raw = request.QueryStringParameters["id"]
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return badRequest("id must be a positive integer"), nil
+	}
+
+	load, err := h.Service.GetLoad(ctx, id)
+	if err != nil {
+		return errorResponse(err), nil
+	}
+
+	return jsonResponse(200, load), nil
+}
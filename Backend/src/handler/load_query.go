@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/service"
+)
+
+// parseLoadQuery reads the view-loads query parameters:
+//
+//	start, pageSize, cursor          pagination; cursor comes from a previous page
+//	status                           comma-separated status names, e.g. "Tendered,Covered"
+//	customerId, carrierId            Turvo account IDs
+//	pickupFrom, pickupTo             pickup date range (RFC 3339 or YYYY-MM-DD)
+//	deliveryFrom, deliveryTo         delivery date range
+//	origin, destination              lane ends, "City, ST"
+//	updatedSince                     only loads changed after this time
+//	sort                             a sort field, prefixed with "-" for descending
+//
+// A cursor only carries the position; filters and sort must be sent again
+// with every page. An explicit pageSize overrides the one stored in the cursor.
+func parseLoadQuery(params map[string]string) (model.LoadQuery, error) {
+	var query model.LoadQuery
+	if token := params["cursor"]; token != "" {
+		var err error
+		if query, err = service.DecodeCursor(token); err != nil {
+			return model.LoadQuery{}, err
+		}
+	} else if v := params["start"]; v != "" {
+		start, err := strconv.Atoi(v)
+		if err != nil || start < 0 {
+			return model.LoadQuery{}, fmt.Errorf("start must be a non-negative integer, got %q", v)
+		}
+		query.Start = start
+	}
+	if v := params["pageSize"]; v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil || pageSize <= 0 {
+			return model.LoadQuery{}, fmt.Errorf("pageSize must be a positive integer, got %q", v)
+		}
+		query.PageSize = pageSize
+	}
+
+	filter, err := parseShipmentFilter(params)
+	if err != nil {
+		return model.LoadQuery{}, err
+	}
+	query.Filter = filter
+
+	if query.Sort, err = parseShipmentSort(params["sort"]); err != nil {
+		return model.LoadQuery{}, err
+	}
+	return query, nil
+}
+
+func parseShipmentFilter(params map[string]string) (model.ShipmentFilter, error) {
+	var (
+		f   model.ShipmentFilter
+		err error
+	)
+	if v := params["status"]; v != "" {
+		for _, name := range strings.Split(v, ",") {
+			code, ok := model.StatusCodeForValue(name)
+			if !ok {
+				return f, fmt.Errorf("status %q is not a known shipment status", strings.TrimSpace(name))
+			}
+			f.Statuses = append(f.Statuses, code)
+		}
+	}
+	if f.CustomerID, err = parseID(params, "customerId"); err != nil {
+		return f, err
+	}
+	if f.CarrierID, err = parseID(params, "carrierId"); err != nil {
+		return f, err
+	}
+	if f.PickupFrom, f.PickupTo, err = parseRange(params, "pickupFrom", "pickupTo"); err != nil {
+		return f, err
+	}
+	if f.DeliveryFrom, f.DeliveryTo, err = parseRange(params, "deliveryFrom", "deliveryTo"); err != nil {
+		return f, err
+	}
+	if f.UpdatedSince, err = parseTime(params, "updatedSince", false); err != nil {
+		return f, err
+	}
+	if f.Origin, err = parseLaneEnd(params, "origin"); err != nil {
+		return f, err
+	}
+	if f.Destination, err = parseLaneEnd(params, "destination"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+func parseShipmentSort(v string) (model.ShipmentSort, error) {
+	if v == "" {
+		return model.ShipmentSort{}, nil
+	}
+	sort := model.ShipmentSort{Field: model.ShipmentSortField(strings.TrimPrefix(v, "-")), Descending: strings.HasPrefix(v, "-")}
+	for _, field := range model.ShipmentSortFields {
+		if sort.Field == field {
+			return sort, nil
+		}
+	}
+	names := make([]string, len(model.ShipmentSortFields))
+	for i, field := range model.ShipmentSortFields {
+		names[i] = string(field)
+	}
+	return model.ShipmentSort{}, fmt.Errorf("sort must be one of %s, optionally prefixed with \"-\"; got %q", strings.Join(names, ", "), v)
+}
+
+func parseID(params map[string]string, name string) (int, error) {
+	v := params[name]
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, got %q", name, v)
+	}
+	return id, nil
+}
+
+// parseRange reads a from/to pair; a date-only "to" covers that whole day.
+func parseRange(params map[string]string, fromName, toName string) (time.Time, time.Time, error) {
+	from, err := parseTime(params, fromName, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseTime(params, toName, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s must not be before %s", toName, fromName)
+	}
+	return from, to, nil
+}
+
+// parseTime accepts RFC 3339 timestamps or YYYY-MM-DD dates (UTC). With
+// endOfDay, a date-only value means the last instant of that day.
+func parseTime(params map[string]string, name string, endOfDay bool) (time.Time, error) {
+	v := params[name]
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date, got %q", name, v)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseLaneEnd normalizes "Chicago,il" to "Chicago, IL", the format used for lanes.
+func parseLaneEnd(params map[string]string, name string) (string, error) {
+	v := strings.TrimSpace(params[name])
+	if v == "" {
+		return "", nil
+	}
+	parts := strings.Split(v, ",")
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || len(strings.TrimSpace(parts[1])) != 2 {
+		return "", fmt.Errorf("%s must look like \"City, ST\", got %q", name, v)
+	}
+	return strings.TrimSpace(parts[0]) + ", " + strings.ToUpper(strings.TrimSpace(parts[1])), nil
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLoadQuery_Filters(t *testing.T) {
+	query, err := parseLoadQuery(map[string]string{
+		"start":        "24",
+		"pageSize":     "12",
+		"status":       "Tendered, covered",
+		"customerId":   "973069",
+		"carrierId":    "834187",
+		"pickupFrom":   "2025-08-01",
+		"pickupTo":     "2025-08-31",
+		"deliveryFrom": "2025-08-02T06:00:00-05:00",
+		"origin":       "Chicago,il",
+		"destination":  "Los Angeles, CA",
+		"updatedSince": "2025-08-19T00:00:00Z",
+		"sort":         "-pickupDate",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 24, query.Start)
+	assert.Equal(t, 12, query.PageSize)
+	assert.Equal(t, model.ShipmentFilter{
+		Statuses:     []model.ShipmentStatusCode{model.StatusTendered, model.StatusCovered},
+		CustomerID:   973069,
+		CarrierID:    834187,
+		PickupFrom:   time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		PickupTo:     time.Date(2025, 8, 31, 23, 59, 59, 999999999, time.UTC),
+		DeliveryFrom: time.Date(2025, 8, 2, 6, 0, 0, 0, time.FixedZone("", -5*3600)),
+		Origin:       "Chicago, IL",
+		Destination:  "Los Angeles, CA",
+		UpdatedSince: time.Date(2025, 8, 19, 0, 0, 0, 0, time.UTC),
+	}, query.Filter)
+	assert.Equal(t, model.ShipmentSort{Field: model.SortByPickupDate, Descending: true}, query.Sort)
+}
+
+func TestParseLoadQuery_RejectsInvalidParameters(t *testing.T) {
+	cases := map[string]map[string]string{
+		"unknown status":   {"status": "Tendered,Lost"},
+		"bad customer":     {"customerId": "abc"},
+		"negative carrier": {"carrierId": "-4"},
+		"bad date":         {"pickupFrom": "08/01/2025"},
+		"inverted range":   {"deliveryFrom": "2025-08-10", "deliveryTo": "2025-08-01"},
+		"bad lane":         {"origin": "Chicago"},
+		"unknown sort":     {"sort": "weight"},
+	}
+	for name, params := range cases {
+		_, err := parseLoadQuery(params)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,122 @@
+// Package handler serves the load endpoints as Lambda handlers. Errors are
+// returned as a JSON body with a code and message, the JSON path of every
+// invalid field, and Turvo's own error when it refused the call.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/model"
+	logger "drumkit.com/interview/src/utils"
+	"drumkit.com/interview/src/validation"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// ErrorBody is the JSON body returned for every failed request.
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// UpstreamStatus is the status Turvo answered with, when the failure came from Turvo.
+	UpstreamStatus int                   `json:"upstreamStatus,omitempty"`
+	Upstream       *gateway.ErrorPayload `json:"upstream,omitempty"`
+	// Candidates lists the records an ambiguous lookup matched.
+	Candidates []model.Candidate `json:"candidates,omitempty"`
+	// Fields lists the invalid fields of a rejected request body.
+	Fields []validation.FieldError `json:"fields,omitempty"`
+}
+
+// statusForKind maps a TMS error kind to the status returned to our caller.
+// Auth failures are ours, not the caller's, so they surface as a bad gateway.
+var statusForKind = map[gateway.ErrorKind]int{
+	gateway.KindValidation:  http.StatusUnprocessableEntity,
+	gateway.KindNotFound:    http.StatusNotFound,
+	gateway.KindAuth:        http.StatusBadGateway,
+	gateway.KindRateLimited: http.StatusTooManyRequests,
+	gateway.KindUnavailable: http.StatusServiceUnavailable,
+	gateway.KindConflict:    http.StatusConflict,
+	gateway.KindUpstream:    http.StatusBadGateway,
+}
+
+func jsonResponse(status int, v interface{}) events.APIGatewayProxyResponse {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
+}
+
+// badRequest is returned when the request itself cannot be understood.
+func badRequest(message string) events.APIGatewayProxyResponse {
+	return jsonResponse(http.StatusBadRequest, ErrorBody{Error: ErrorDetail{Code: "bad_request", Message: message}})
+}
+
+// errorResponse maps err to a status code and a structured JSON error body.
+func errorResponse(err error) events.APIGatewayProxyResponse {
+	status, detail := errorDetail(err)
+	if logger.Logger != nil {
+		logger.Logger.Error("request failed", zap.Int("status", status), zap.Error(err))
+	}
+
+	body, _ := json.Marshal(ErrorBody{Error: detail})
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
+}
+
+// internalErrorMessage is returned for unexpected errors, whose text may hold
+// internals the caller should not see. Callers log the full error.
+const internalErrorMessage = "internal error"
+
+// errorDetail maps err to a status code and the error returned for it.
+func errorDetail(err error) (int, ErrorDetail) {
+	status := http.StatusInternalServerError
+	detail := ErrorDetail{Code: "internal_error", Message: err.Error()}
+
+	var turvoErr *gateway.TurvoError
+	var transitionErr *model.TransitionError
+	var ambiguousErr *model.AmbiguousMatchError
+	var fieldErrs validation.Errors
+	var patchErr *model.PatchError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		detail.Code = "timeout"
+	case errors.As(err, &turvoErr):
+		status = statusForKind[turvoErr.Kind]
+		detail.Code = string(turvoErr.Kind)
+		detail.UpstreamStatus = turvoErr.StatusCode
+		detail.Upstream = turvoErr.Payload
+	case errors.As(err, &transitionErr):
+		status = http.StatusConflict
+		detail.Code = "invalid_transition"
+	case errors.As(err, &ambiguousErr):
+		status = http.StatusConflict
+		detail.Code = "ambiguous_match"
+		detail.Candidates = ambiguousErr.Candidates
+	case errors.As(err, &fieldErrs):
+		status = http.StatusUnprocessableEntity
+		detail.Code = string(gateway.KindValidation)
+		detail.Fields = fieldErrs
+	case errors.As(err, &patchErr):
+		status = http.StatusBadRequest
+		detail.Code = "bad_request"
+	default:
+		detail.Message = internalErrorMessage
+	}
+	return status, detail
+}
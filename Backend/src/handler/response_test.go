@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse_MapsTurvoErrors(t *testing.T) {
+	payload := &gateway.ErrorPayload{Status: "ERROR"}
+	payload.Details.ErrorMessage = "Shipment not found"
+
+	resp := errorResponse(fmt.Errorf("get load: %w", &gateway.TurvoError{
+		Kind:       gateway.KindNotFound,
+		Op:         "get shipment",
+		StatusCode: http.StatusNotFound,
+		Payload:    payload,
+	}))
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
+
+	var body ErrorBody
+	require.NoError(t, json.Unmarshal([]byte(resp.Body), &body))
+	assert.Equal(t, "not_found", body.Error.Code)
+	assert.Equal(t, http.StatusNotFound, body.Error.UpstreamStatus)
+	assert.Equal(t, "Shipment not found", body.Error.Upstream.Details.ErrorMessage)
+}
+
+func TestErrorResponse_StatusPerKind(t *testing.T) {
+	for kind, status := range statusForKind {
+		resp := errorResponse(&gateway.TurvoError{Kind: kind, Op: "op"})
+		assert.Equal(t, status, resp.StatusCode, string(kind))
+	}
+}
+
+func TestErrorResponse_Timeout(t *testing.T) {
+	resp := errorResponse(&gateway.TurvoError{Kind: gateway.KindUnavailable, Op: "list shipments", Err: context.DeadlineExceeded})
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+}
+
+func TestErrorResponse_InvalidTransition(t *testing.T) {
+	resp := errorResponse(&model.TransitionError{From: model.StatusDelivered, To: model.StatusTendered})
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.JSONEq(t, `{"error":{"code":"invalid_transition","message":"cannot move a load from Delivered to Tendered"}}`, resp.Body)
+}
+
+func TestErrorResponse_AmbiguousMatch(t *testing.T) {
+	resp := errorResponse(&model.AmbiguousMatchError{
+		Entity:     "customer",
+		By:         `name "Bunker"`,
+		Candidates: []model.Candidate{{ID: 1, Name: "Bunker"}, {ID: 2, Name: "Bunker"}},
+	})
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.JSONEq(t, `{"error":{
+		"code":"ambiguous_match",
+		"message":"name \"Bunker\" matches 2 customers: Bunker (1), Bunker (2)",
+		"candidates":[{"id":1,"name":"Bunker"},{"id":2,"name":"Bunker"}]
+	}}`, resp.Body)
+}
+
+func TestErrorResponse_InvalidFields(t *testing.T) {
+	resp := errorResponse(validation.Errors{{Field: "pickup.apptTime", Message: "is required"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.JSONEq(t, `{"error":{
+		"code":"validation",
+		"message":"invalid request: pickup.apptTime is required",
+		"fields":[{"field":"pickup.apptTime","message":"is required"}]
+	}}`, resp.Body)
+}
+
+func TestErrorResponse_InvalidPatch(t *testing.T) {
+	_, err := model.LoadPatch(`{"totalWeight":"heavy"}`).Apply(model.CreateLoadRequest{})
+	resp := errorResponse(fmt.Errorf("update load 7: %w", err))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resp.Body, `"code":"bad_request"`)
+	assert.Contains(t, resp.Body, "totalWeight")
+}
+
+func TestErrorResponse_UnknownError(t *testing.T) {
+	resp := errorResponse(errors.New("boom"))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.JSONEq(t, `{"error":{"code":"internal_error","message":"internal error"}}`, resp.Body)
+}
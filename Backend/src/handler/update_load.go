@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/service"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type UpdateLoadHandler struct {
+	Service *service.LoadService
+}
+
+// UpdateLoadHandlerLambda applies a partial load body to the load named by
+// the {id} path parameter and returns the updated load.
+func (h *UpdateLoadHandler) UpdateLoadHandlerLambda(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
+
+	id, err := strconv.Atoi(request.PathParameters["id"])
+	if err != nil || id <= 0 {
+		return badRequest("id must be a positive integer"), nil
+	}
+
+	patch := model.LoadPatch(request.Body)
+	if err := patch.Check(); err != nil {
+		return errorResponse(err), nil
+	}
+
+	load, err := h.Service.UpdateLoad(ctx, id, patch)
+	if err != nil {
+		return errorResponse(err), nil
+	}
+
+	return jsonResponse(200, load), nil
+}
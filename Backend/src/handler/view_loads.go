@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
 
 	"drumkit.com/interview/src/service"
 	"github.com/aws/aws-lambda-go/events"
@@ -11,26 +11,21 @@ type ViewLoadsHandler struct {
 	Service *service.LoadService
 }
 
-func (h *ViewLoadsHandler) ViewLoadsHandlerLambda(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func (h *ViewLoadsHandler) ViewLoadsHandlerLambda(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
 
-	// Call the service to retrieve loads
 	// Extract query parameters from the request
-	params := request.QueryStringParameters
-
-	// Pass the query params to the service method
-	loads, err := h.Service.RetrieveLoads(params["start"], params["pageSize"])
+	query, err := parseLoadQuery(request.QueryStringParameters)
 	if err != nil {
-		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		return badRequest(err.Error()), nil
 	}
 
-	// Marshal the loads into a JSON string to be returned in the response body
-	responseBody, err := json.Marshal(loads)
+	// Pass the query to the service method
+	page, err := h.Service.RetrieveLoads(ctx, query)
 	if err != nil {
-		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		return errorResponse(err), nil
 	}
 
-	return events.APIGatewayProxyResponse{
-		StatusCode: 200,
-		Body:       string(responseBody),
-	}, nil
+	return jsonResponse(200, page), nil
 }
@@ -0,0 +1,193 @@
+// Package lookup holds the keys of the Turvo lookup values that tenants can
+// configure differently: the stop scheduling types, the line item codes rates
+// are charged under and the stop service and line item each accessorial is
+// sent as. The built-in keys in default.json have not been checked against
+// every tenant, so a tenant can replace any of them with a JSON file of the
+// same shape:
+//
+//	{"scheduling": {
+//	  "fcfs": {"key": "...", "value": "First come, first served"},
+//	  "appointment": {"key": "...", "value": "By appointment"}
+//	 },
+//	 "lineItems": {"fuelPercent": {"key": "...", "value": "Fuel surcharge"}},
+//	 "accessorials": {
+//	  "liftgatePickup": {"service": {"key": "...", "value": "Liftgate"},
+//	                     "charge": {"key": "...", "value": "Liftgate"}}
+//	}}
+//
+// Keys the file leaves out keep their built-in values. A Lambda serves a
+// single tenant, so its main loads the keys once with ForTenant and installs
+// them with Use before handling requests.
+package lookup
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"drumkit.com/interview/src/model"
+)
+
+//go:embed default.json
+var defaultKeys []byte
+
+var (
+	defaultOnce sync.Once
+	defaults    Keys
+	current     atomic.Pointer[Keys]
+)
+
+// Keys are the Turvo lookup values sent for a tenant.
+type Keys struct {
+	Scheduling Scheduling `json:"scheduling"`
+	LineItems  LineItems  `json:"lineItems"`
+	// Accessorials are keyed by the CLSpecifications JSON field that
+	// requests them.
+	Accessorials map[string]Accessorial `json:"accessorials"`
+}
+
+// Scheduling are the scheduling types of a route stop.
+type Scheduling struct {
+	FCFS        model.ValueKey `json:"fcfs"`
+	Appointment model.ValueKey `json:"appointment"`
+}
+
+// LineItems are the line item codes the linehaul and fuel surcharge are
+// charged under. Each must be distinct, since line items are read back by code.
+type LineItems struct {
+	FreightFlat    model.ValueKey `json:"freightFlat"`
+	FreightPerMile model.ValueKey `json:"freightPerMile"`
+	FreightHourly  model.ValueKey `json:"freightHourly"`
+	FuelPercent    model.ValueKey `json:"fuelPercent"`
+	FuelPerMile    model.ValueKey `json:"fuelPerMile"`
+}
+
+// Accessorial is the stop service an accessorial is set as and the line item
+// code it is charged under.
+type Accessorial struct {
+	Service model.ValueKey `json:"service"`
+	Charge  model.ValueKey `json:"charge"`
+}
+
+// Default returns the built-in keys.
+func Default() Keys {
+	defaultOnce.Do(func() {
+		dec := json.NewDecoder(bytes.NewReader(defaultKeys))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&defaults); err != nil {
+			panic(fmt.Errorf("lookup keys: %w", err))
+		}
+		if err := defaults.validate(); err != nil {
+			panic(fmt.Errorf("lookup keys: %w", err))
+		}
+	})
+	return defaults.clone()
+}
+
+// Load reads a key file over the built-in keys and validates the result.
+func Load(r io.Reader) (Keys, error) {
+	keys := Default()
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&keys); err != nil {
+		return Keys{}, fmt.Errorf("lookup keys: %w", err)
+	}
+	if err := keys.validate(); err != nil {
+		return Keys{}, fmt.Errorf("lookup keys: %w", err)
+	}
+	return keys, nil
+}
+
+// LoadFile reads the key file at path over the built-in keys.
+func LoadFile(path string) (Keys, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Keys{}, fmt.Errorf("lookup keys: %w", err)
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// ForTenant loads the keys configured for tenant. It reads the file named by
+// TURVO_LOOKUPS_<TENANT> first, then TURVO_LOOKUPS, and falls back to Default.
+func ForTenant(tenant string) (Keys, error) {
+	if tenant != "" {
+		key := "TURVO_LOOKUPS_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(tenant))
+		if path := os.Getenv(key); path != "" {
+			return LoadFile(path)
+		}
+	}
+	if path := os.Getenv("TURVO_LOOKUPS"); path != "" {
+		return LoadFile(path)
+	}
+	return Default(), nil
+}
+
+// Use installs keys as the ones Current returns.
+func Use(keys Keys) {
+	keys = keys.clone()
+	current.Store(&keys)
+}
+
+// Current returns the keys installed with Use, or the built-in keys. Callers
+// must not change the maps of the keys returned.
+func Current() Keys {
+	if keys := current.Load(); keys != nil {
+		return *keys
+	}
+	Default()
+	return defaults
+}
+
+// clone copies k so changes to its maps are not shared.
+func (k Keys) clone() Keys {
+	accessorials := make(map[string]Accessorial, len(k.Accessorials))
+	for name, a := range k.Accessorials {
+		accessorials[name] = a
+	}
+	k.Accessorials = accessorials
+	return k
+}
+
+func (k Keys) validate() error {
+	required := map[string]model.ValueKey{
+		"scheduling.fcfs":          k.Scheduling.FCFS,
+		"scheduling.appointment":   k.Scheduling.Appointment,
+		"lineItems.freightFlat":    k.LineItems.FreightFlat,
+		"lineItems.freightPerMile": k.LineItems.FreightPerMile,
+		"lineItems.freightHourly":  k.LineItems.FreightHourly,
+		"lineItems.fuelPercent":    k.LineItems.FuelPercent,
+		"lineItems.fuelPerMile":    k.LineItems.FuelPerMile,
+	}
+	for name, a := range k.Accessorials {
+		required["accessorials."+name+".service"] = a.Service
+		required["accessorials."+name+".charge"] = a.Charge
+	}
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lineItems := map[string]string{}
+	for _, name := range names {
+		key := required[name].Key
+		if key == "" {
+			return fmt.Errorf("%s: key is required", name)
+		}
+		if !strings.HasPrefix(name, "lineItems.") {
+			continue
+		}
+		if other, ok := lineItems[key]; ok {
+			return fmt.Errorf("%s: key %s is already used by %s", name, key, other)
+		}
+		lineItems[key] = name
+	}
+	return nil
+}
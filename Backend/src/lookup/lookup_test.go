@@ -0,0 +1,71 @@
+package lookup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_KeepsBuiltInKeysLeftOut(t *testing.T) {
+	keys, err := Load(strings.NewReader(`{"scheduling": {"fcfs": {"key": "9700", "value": "FCFS"}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, model.ValueKey{Key: "9700", Value: "FCFS"}, keys.Scheduling.FCFS)
+	assert.Equal(t, Default().Scheduling.Appointment, keys.Scheduling.Appointment)
+}
+
+func TestLoad_ReplacesAccessorials(t *testing.T) {
+	keys, err := Load(strings.NewReader(`{"accessorials": {"labor": {"service": {"key": "S1"}, "charge": {"key": "C1"}}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "S1", keys.Accessorials["labor"].Service.Key)
+	assert.Equal(t, Default().Accessorials["seal"], keys.Accessorials["seal"])
+	assert.Equal(t, "3107", Default().Accessorials["labor"].Service.Key, "the built-in keys are not changed")
+
+	_, err = Load(strings.NewReader(`{"accessorials": {"labor": {"service": {"key": "S1"}}}}`))
+	assert.ErrorContains(t, err, "accessorials.labor.charge: key is required")
+}
+
+func TestLoad_RejectsInvalidKeys(t *testing.T) {
+	for name, body := range map[string]string{
+		"empty key":           `{"scheduling": {"fcfs": {"key": "", "value": "FCFS"}}}`,
+		"unknown field":       `{"scheduling": {"later": {"key": "9702"}}}`,
+		"malformed JSON":      `{"scheduling": `,
+		"shared line item":    `{"lineItems": {"fuelPerMile": {"key": "1604"}}}`,
+		"line item left open": `{"lineItems": {"freightHourly": {"key": ""}}}`,
+	} {
+		_, err := Load(strings.NewReader(body))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestForTenant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acme.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"scheduling": {"appointment": {"key": "9701"}}}`), 0o600))
+
+	t.Setenv("TURVO_LOOKUPS_ACME_CO", path)
+	keys, err := ForTenant("acme-co")
+	require.NoError(t, err)
+	assert.Equal(t, "9701", keys.Scheduling.Appointment.Key)
+
+	keys, err = ForTenant("other")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), keys)
+
+	t.Setenv("TURVO_LOOKUPS", filepath.Join(t.TempDir(), "missing.json"))
+	_, err = ForTenant("other")
+	assert.Error(t, err)
+}
+
+func TestUse(t *testing.T) {
+	t.Cleanup(func() { Use(Default()) })
+	assert.Equal(t, Default(), Current())
+
+	keys := Default()
+	keys.Scheduling.FCFS.Key = "9700"
+	Use(keys)
+	assert.Equal(t, "9700", Current().Scheduling.FCFS.Key)
+}
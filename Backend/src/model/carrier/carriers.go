@@ -0,0 +1,49 @@
+package carrier
+
+// Models for Turvo carriers list response
+
+type CarriersResponse struct {
+	Status  string          `json:"Status"`
+	Details CarriersDetails `json:"details"`
+}
+
+type CarriersDetails struct {
+	Pagination Pagination `json:"pagination"`
+	Carriers   []Carrier  `json:"carriers"`
+}
+
+type Pagination struct {
+	Start              int     `json:"start"`
+	PageSize           int     `json:"pageSize"`
+	TotalRecordsInPage int     `json:"totalRecordsInPage"`
+	MoreAvailable      bool    `json:"moreAvailable"`
+	LastObjectKey      *string `json:"lastObjectKey"`
+}
+
+type Carrier struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	McNumber  string `json:"mcNumber"`
+	DotNumber string `json:"dotNumber"`
+	Scac      string `json:"scac"`
+	Status    Status `json:"status"`
+}
+
+type Status struct {
+	Code        ValueKey `json:"code"`
+	Description string   `json:"description"`
+}
+
+type ValueKey struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// Query selects carriers by their Turvo ID or one of their public
+// identifiers. Empty fields are not applied.
+type Query struct {
+	ID        int
+	McNumber  string
+	DotNumber string
+	Scac      string
+}
@@ -1,6 +1,10 @@
 package model
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // CreateLoadRequest is the top-level struct for the create load request body.
 type CreateLoadRequest struct {
@@ -11,6 +15,7 @@ type CreateLoadRequest struct {
 	BillTo            CLBillTo         `json:"billTo"`
 	Pickup            CLPickup         `json:"pickup"`
 	Consignee         CLConsignee      `json:"consignee"`
+	Stops             []CLStop         `json:"stops,omitempty"`
 	Carrier           CLCarrier        `json:"carrier"`
 	RateData          CLRateData       `json:"rateData"`
 	Specifications    CLSpecifications `json:"specifications"`
@@ -100,6 +105,150 @@ type CLConsignee struct {
 	WarehouseId   string `json:"warehouseId"`
 }
 
+// Stop types accepted in CLStop.Type.
+const (
+	StopTypePickup   = "pickup"
+	StopTypeDelivery = "delivery"
+)
+
+// CLStop is one stop on a multi-stop route. When a request has stops they
+// replace the pickup and consignee objects.
+type CLStop struct {
+	Type          string `json:"type"`
+	ExternalTMSId string `json:"externalTMSId"`
+	Name          string `json:"name"`
+	AddressLine1  string `json:"addressLine1"`
+	AddressLine2  string `json:"addressLine2"`
+	City          string `json:"city"`
+	State         string `json:"state"`
+	Zipcode       string `json:"zipcode"`
+	Country       string `json:"country"`
+	Contact       string `json:"contact"`
+	Phone         string `json:"phone"`
+	Email         string `json:"email"`
+	BusinessHours string `json:"businessHours"`
+	RefNumber     string `json:"refNumber"`
+	// ReadyTime opens the window at a pickup and MustDeliver closes it at a
+	// delivery.
+	ReadyTime   string `json:"readyTime,omitempty"`
+	MustDeliver string `json:"mustDeliver,omitempty"`
+	ApptTime    string `json:"apptTime"`
+	ApptNote    string `json:"apptNote"`
+	Timezone    string `json:"timezone"`
+	WarehouseId string `json:"warehouseId"`
+}
+
+// RouteStops returns the load's stops in route order: Stops when set,
+// otherwise the pickup followed by the consignee. A route must start with a
+// pickup and end with a delivery.
+func (r CreateLoadRequest) RouteStops() ([]CLStop, error) {
+	stops := r.Stops
+	if len(stops) == 0 {
+		stops = []CLStop{r.Pickup.Stop(), r.Consignee.Stop()}
+	}
+	if len(stops) < 2 {
+		return nil, errors.New("a route needs at least a pickup and a delivery stop")
+	}
+
+	out := make([]CLStop, len(stops))
+	for i, stop := range stops {
+		stop.Type = strings.ToLower(strings.TrimSpace(stop.Type))
+		if stop.Type != StopTypePickup && stop.Type != StopTypeDelivery {
+			return nil, fmt.Errorf("stop %d: type must be %q or %q", i+1, StopTypePickup, StopTypeDelivery)
+		}
+		out[i] = stop
+	}
+	if out[0].Type != StopTypePickup {
+		return nil, errors.New("the first stop must be a pickup")
+	}
+	if out[len(out)-1].Type != StopTypeDelivery {
+		return nil, errors.New("the last stop must be a delivery")
+	}
+	return out, nil
+}
+
+// Stop returns the pickup as a route stop.
+func (p CLPickup) Stop() CLStop {
+	return CLStop{
+		Type:          StopTypePickup,
+		ExternalTMSId: p.ExternalTMSId,
+		Name:          p.Name,
+		AddressLine1:  p.AddressLine1,
+		AddressLine2:  p.AddressLine2,
+		City:          p.City,
+		State:         p.State,
+		Zipcode:       p.Zipcode,
+		Country:       p.Country,
+		Contact:       p.Contact,
+		Phone:         p.Phone,
+		Email:         p.Email,
+		BusinessHours: p.BusinessHours,
+		RefNumber:     p.RefNumber,
+		ReadyTime:     p.ReadyTime,
+		ApptTime:      p.ApptTime,
+		ApptNote:      p.ApptNote,
+		Timezone:      p.Timezone,
+		WarehouseId:   p.WarehouseId,
+	}
+}
+
+// Stop returns the consignee as a route stop.
+func (c CLConsignee) Stop() CLStop {
+	return CLStop{
+		Type:          StopTypeDelivery,
+		ExternalTMSId: c.ExternalTMSId,
+		Name:          c.Name,
+		AddressLine1:  c.AddressLine1,
+		AddressLine2:  c.AddressLine2,
+		City:          c.City,
+		State:         c.State,
+		Zipcode:       c.Zipcode,
+		Country:       c.Country,
+		Contact:       c.Contact,
+		Phone:         c.Phone,
+		Email:         c.Email,
+		BusinessHours: c.BusinessHours,
+		RefNumber:     c.RefNumber,
+		MustDeliver:   c.MustDeliver,
+		ApptTime:      c.ApptTime,
+		ApptNote:      c.ApptNote,
+		Timezone:      c.Timezone,
+		WarehouseId:   c.WarehouseId,
+	}
+}
+
+// LoadRefs are the Turvo IDs a load's references resolved to before it is
+// sent to the TMS.
+type LoadRefs struct {
+	// LocationIDs holds one location ID per route stop, in route order.
+	LocationIDs []int
+	// CustomerID is the matched Turvo customer the customer order is for.
+	CustomerID   int
+	CustomerName string
+	// CarrierID is the matched Turvo carrier, or 0 when the load has none.
+	CarrierID   int
+	CarrierName string
+	// Equipment is the equipment the load ships on. Nil means the TMS picks
+	// it with its default rules.
+	Equipment *Equipment
+}
+
+// CreatedLoad is the shipment a create load request made in the TMS, with
+// the records its references resolved to.
+type CreatedLoad struct {
+	ID       int    `json:"id"`
+	CustomID string `json:"customId"`
+	// LocationIDs holds the location of every route stop, in route order.
+	LocationIDs []int `json:"locationIds"`
+	CustomerID  int   `json:"customerId"`
+	// CarrierID is 0 when the load was created without a carrier.
+	CarrierID int `json:"carrierId,omitempty"`
+	// MatchReviews lists the matches accepted with low confidence, and
+	// MarginFlags the margin problems to review.
+	MatchReviews []MatchReview `json:"matchReviews,omitempty"`
+	MarginFlags  []string      `json:"marginFlags,omitempty"`
+}
+
 // CLCarrier represents the carrier object.
 type CLCarrier struct {
 	McNumber                 string `json:"mcNumber"`
@@ -144,6 +293,9 @@ type CLRateData struct {
 	CarrierMaxRate    float64 `json:"carrierMaxRate"`
 	NetProfitUsd      float64 `json:"netProfitUsd"`
 	ProfitPercent     float64 `json:"profitPercent"`
+	// Accessorials are the customer rates for requested accessorial services,
+	// keyed by their specifications field, e.g. "liftgateDelivery".
+	Accessorials map[string]float64 `json:"accessorials,omitempty"`
 }
 
 // CLSpecifications represents the specifications object.
@@ -163,6 +315,8 @@ type CLSpecifications struct {
 	Seal              bool `json:"seal"`
 	CustomBonded      bool `json:"customBonded"`
 	Labor             bool `json:"labor"`
+	// PowerOnly books a tractor to pull a trailer the shipper provides.
+	PowerOnly bool `json:"powerOnly"`
 }
 
 type ShipmentStatusCode string
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteStops_FallsBackToPickupAndConsignee(t *testing.T) {
+	load := CreateLoadRequest{
+		Pickup:    CLPickup{Name: "Shipper", City: "Chicago", ApptTime: "2025-08-01T08:00:00Z"},
+		Consignee: CLConsignee{Name: "Receiver", City: "Dallas", ApptTime: "2025-08-02T08:00:00Z"},
+	}
+
+	stops, err := load.RouteStops()
+	require.NoError(t, err)
+	assert.Equal(t, []CLStop{
+		{Type: StopTypePickup, Name: "Shipper", City: "Chicago", ApptTime: "2025-08-01T08:00:00Z"},
+		{Type: StopTypeDelivery, Name: "Receiver", City: "Dallas", ApptTime: "2025-08-02T08:00:00Z"},
+	}, stops)
+}
+
+func TestRouteStops_UsesStops(t *testing.T) {
+	load := CreateLoadRequest{
+		Pickup: CLPickup{Name: "ignored"},
+		Stops: []CLStop{
+			{Type: "Pickup", Name: "A"},
+			{Type: " pickup ", Name: "B"},
+			{Type: "DELIVERY", Name: "C"},
+		},
+	}
+
+	stops, err := load.RouteStops()
+	require.NoError(t, err)
+	require.Len(t, stops, 3)
+	assert.Equal(t, []string{StopTypePickup, StopTypePickup, StopTypeDelivery}, []string{stops[0].Type, stops[1].Type, stops[2].Type})
+	assert.Equal(t, "Pickup", load.Stops[0].Type, "the request itself is not modified")
+}
+
+func TestRouteStops_RejectsInvalidRoutes(t *testing.T) {
+	cases := map[string][]CLStop{
+		"single stop":        {{Type: StopTypePickup}},
+		"unknown type":       {{Type: StopTypePickup}, {Type: "crossdock"}, {Type: StopTypeDelivery}},
+		"starts with drop":   {{Type: StopTypeDelivery}, {Type: StopTypeDelivery}},
+		"ends with a pickup": {{Type: StopTypePickup}, {Type: StopTypeDelivery}, {Type: StopTypePickup}},
+	}
+	for name, stops := range cases {
+		_, err := CreateLoadRequest{Stops: stops}.RouteStops()
+		assert.Error(t, err, name)
+	}
+}
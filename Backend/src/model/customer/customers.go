@@ -127,3 +127,13 @@ type BillingsLookup struct {
 		State []string `json:"state,omitempty"`
 	} `json:"address,omitempty"`
 }
+
+// Query selects customers. Empty fields are not applied.
+type Query struct {
+	ID         int
+	Name       string
+	ExternalID string
+	City       string
+	State      string
+	Zip        string
+}
@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// LoadQuery selects a page of shipments from the TMS. Zero values leave the
+// TMS defaults in place.
+type LoadQuery struct {
+	Start         int
+	PageSize      int
+	LastObjectKey string
+	Filter        ShipmentFilter
+	Sort          ShipmentSort
+}
+
+// ShipmentFilter narrows the shipment list. Zero-valued fields are not applied.
+type ShipmentFilter struct {
+	Statuses     []ShipmentStatusCode
+	CustomerID   int
+	CarrierID    int
+	PickupFrom   time.Time
+	PickupTo     time.Time
+	DeliveryFrom time.Time
+	DeliveryTo   time.Time
+	// Origin and Destination match the lane ends, formatted "City, ST".
+	Origin       string
+	Destination  string
+	UpdatedSince time.Time
+}
+
+// ShipmentSortField is a field the shipment list can be sorted by.
+type ShipmentSortField string
+
+const (
+	SortByPickupDate   ShipmentSortField = "pickupDate"
+	SortByDeliveryDate ShipmentSortField = "deliveryDate"
+	SortByCreated      ShipmentSortField = "created"
+	SortByUpdated      ShipmentSortField = "updated"
+	SortByStatus       ShipmentSortField = "status"
+	SortByCustomID     ShipmentSortField = "customId"
+)
+
+// ShipmentSortFields lists the accepted sort fields.
+var ShipmentSortFields = []ShipmentSortField{
+	SortByPickupDate, SortByDeliveryDate, SortByCreated, SortByUpdated, SortByStatus, SortByCustomID,
+}
+
+// ShipmentSort orders the shipment list. An empty Field keeps the TMS default order.
+type ShipmentSort struct {
+	Field      ShipmentSortField
+	Descending bool
+}
+
+// LoadPage is one page of loads returned by the view-loads endpoint.
+type LoadPage struct {
+	Loads      []Shipment `json:"loads"`
+	Pagination PageInfo   `json:"pagination"`
+}
+
+// PageInfo describes where a LoadPage sits in the full result set. NextCursor
+// is opaque to clients and is only set when MoreAvailable is true.
+type PageInfo struct {
+	Start         int    `json:"start"`
+	PageSize      int    `json:"pageSize"`
+	Count         int    `json:"count"`
+	MoreAvailable bool   `json:"moreAvailable"`
+	NextCursor    string `json:"nextCursor,omitempty"`
+}
@@ -1,6 +1,13 @@
 package locations
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// BusinessHoursNote starts the line of a location's notes that records its
+// business hours.
+const BusinessHoursNote = "Business hours: "
 
 type LocationsResponse struct {
 	Status  string          `json:"Status"`
@@ -26,14 +33,27 @@ type Location struct {
 	Updated   time.Time `json:"updated"`
 	Addresses []Address `json:"addresses"`
 	Phones    []Phone   `json:"phones"`
+	Notes     string    `json:"notes,omitempty"`
+}
+
+// BusinessHours returns the business hours recorded in the location's notes,
+// or "" when it has none.
+func (l Location) BusinessHours() string {
+	for _, line := range strings.Split(l.Notes, "\n") {
+		if strings.HasPrefix(line, BusinessHoursNote) {
+			return strings.TrimSpace(strings.TrimPrefix(line, BusinessHoursNote))
+		}
+	}
+	return ""
 }
 
 type Address struct {
-	Line1 string `json:"line1"`
-	Line2 string `json:"line2"`
-	City  string `json:"city"`
-	State string `json:"state"`
-	Zip   string `json:"zip"`
+	Line1   string `json:"line1"`
+	Line2   string `json:"line2"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Zip     string `json:"zip"`
+	Country string `json:"country,omitempty"`
 }
 
 type Phone struct {
@@ -41,3 +61,31 @@ type Phone struct {
 	Number      string `json:"number"`
 	Extension   string `json:"extension"`
 }
+
+// LocationResponse is the body returned for a single location.
+type LocationResponse struct {
+	Status  string   `json:"Status"`
+	Details Location `json:"details"`
+}
+
+// CreateRequest is the body of POST /locations.
+type CreateRequest struct {
+	Name      string    `json:"name"`
+	Timezone  string    `json:"timezone,omitempty"`
+	Addresses []Address `json:"addresses"`
+	Phones    []Phone   `json:"phones,omitempty"`
+	Emails    []Email   `json:"emails,omitempty"`
+	Contacts  []Contact `json:"contacts,omitempty"`
+	// Notes carries the free-text business hours and reference number.
+	Notes string `json:"notes,omitempty"`
+}
+
+type Email struct {
+	Email string `json:"email"`
+}
+
+type Contact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Candidate is one of several TMS records that matched a lookup.
+type Candidate struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// AmbiguousMatchError reports a customer, carrier or location lookup that
+// matched more than one TMS record, so none could be picked.
+type AmbiguousMatchError struct {
+	// Entity is what was looked up, e.g. "customer".
+	Entity string
+	// By describes the identifier that matched, e.g. `name "Bunker"`.
+	By         string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = fmt.Sprintf("%s (%d)", c.Name, c.ID)
+	}
+	return fmt.Sprintf("%s matches %d %ss: %s", e.By, len(e.Candidates), e.Entity, strings.Join(names, ", "))
+}
+
+// MatchReview flags a lookup that was accepted with low confidence and should
+// be checked by a person.
+type MatchReview struct {
+	Entity string    `json:"entity"`
+	Query  string    `json:"query"`
+	Match  Candidate `json:"match"`
+	// Score is the match confidence from 0 to 1.
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
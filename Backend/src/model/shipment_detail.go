@@ -0,0 +1,67 @@
+package model
+
+// ShipmentDetailResponse is the envelope returned by GET /shipments/{id}.
+type ShipmentDetailResponse struct {
+	Status  string         `json:"Status"`
+	Details ShipmentDetail `json:"details"`
+}
+
+// ShipmentDetail is a single Turvo shipment with its route, equipment and
+// customer and carrier orders.
+type ShipmentDetail struct {
+	ID            int                   `json:"id"`
+	CustomID      string                `json:"customId"`
+	LTLShipment   bool                  `json:"ltlShipment"`
+	StartDate     DateTime              `json:"startDate"`
+	EndDate       DateTime              `json:"endDate"`
+	Status        AvroStatus            `json:"status"`
+	Groups        []Group               `json:"groups"`
+	Equipment     []Equipment           `json:"equipment"`
+	Lane          Lane                  `json:"lane"`
+	GlobalRoute   []RouteStop           `json:"globalRoute"`
+	ModeInfo      []ModeInfo            `json:"modeInfo"`
+	CustomerOrder []CustomerOrderDetail `json:"customerOrder"`
+	CarrierOrder  []CarrierOrderDetail  `json:"carrierOrder"`
+	Created       string                `json:"created"`
+	Updated       string                `json:"updated"`
+}
+
+// RouteStop is a stop on the shipment's global route as Turvo returns it,
+// with the stop ID and the resolved address of its location.
+type RouteStop struct {
+	ID int `json:"id"`
+	GlobalRoute
+	Address StopAddress `json:"address"`
+}
+
+// StopAddress is the address Turvo attaches to a route stop.
+type StopAddress struct {
+	Line1   string `json:"line1"`
+	Line2   string `json:"line2"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Zip     string `json:"zip"`
+	Country string `json:"country"`
+}
+
+// CustomerOrderDetail is a customer order on a stored shipment.
+type CustomerOrderDetail struct {
+	ID      int  `json:"id"`
+	Deleted bool `json:"deleted"`
+	CustomerOrderAvro
+}
+
+// CarrierOrderDetail is a carrier order on a stored shipment.
+type CarrierOrderDetail struct {
+	ID      int  `json:"id"`
+	Deleted bool `json:"deleted"`
+	CarrierOrderAvro
+}
+
+// LoadDetail is a single load in the Drumkit format, as returned by the
+// get-load endpoint.
+type LoadDetail struct {
+	ID       int    `json:"id"`
+	CustomID string `json:"customId"`
+	CreateLoadRequest
+}
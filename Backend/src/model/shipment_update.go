@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Turvo _operation values for entries in an update payload.
+const (
+	OperationInsert = 0
+	OperationUpdate = 1
+	OperationDelete = 2
+)
+
+// ShipmentUpdate is the body of PUT /shipments/{id}. Nil and empty sections
+// are left untouched by Turvo; array entries carry an _operation and, unless
+// inserted, the ID of the entry they change.
+type ShipmentUpdate struct {
+	LTLShipment   *bool                 `json:"ltlShipment,omitempty"`
+	StartDate     *DateTime             `json:"startDate,omitempty"`
+	EndDate       *DateTime             `json:"endDate,omitempty"`
+	Equipment     []Equipment           `json:"equipment,omitempty"`
+	GlobalRoute   []StopUpdate          `json:"globalRoute,omitempty"`
+	CustomerOrder []CustomerOrderUpdate `json:"customerOrder,omitempty"`
+	CarrierOrder  []CarrierOrderUpdate  `json:"carrierOrder,omitempty"`
+}
+
+// Empty reports whether the update changes nothing.
+func (u ShipmentUpdate) Empty() bool {
+	return u.LTLShipment == nil && u.StartDate == nil && u.EndDate == nil &&
+		len(u.Equipment) == 0 && len(u.GlobalRoute) == 0 &&
+		len(u.CustomerOrder) == 0 && len(u.CarrierOrder) == 0
+}
+
+// StopUpdate changes the appointment, window, notes or services of an existing
+// route stop. Services replaces the stop's whole service list.
+type StopUpdate struct {
+	ID                     int                     `json:"id"`
+	Operation              int                     `json:"_operation"`
+	Appointment            *Appointment            `json:"appointment,omitempty"`
+	SchedulingType         *ValueKey               `json:"schedulingType,omitempty"`
+	PlannedAppointmentDate *PlannedAppointmentDate `json:"plannedAppointmentDate,omitempty"`
+	Notes                  *string                 `json:"notes,omitempty"`
+	Services               *[]ValueKey             `json:"services,omitempty"`
+}
+
+// CustomerOrderUpdate changes the items, costs or external IDs of an existing customer order.
+type CustomerOrderUpdate struct {
+	ID          int          `json:"id"`
+	Operation   int          `json:"_operation"`
+	Items       []Item       `json:"items,omitempty"`
+	Costs       *Costs       `json:"costs,omitempty"`
+	ExternalIds []ExternalID `json:"externalIds,omitempty"`
+}
+
+// CarrierOrderUpdate changes the costs of an existing carrier order.
+type CarrierOrderUpdate struct {
+	ID        int    `json:"id"`
+	Operation int    `json:"_operation"`
+	Costs     *Costs `json:"costs,omitempty"`
+}
+
+// LoadPatch is a partial CreateLoadRequest body. Only the fields present in
+// the JSON change; nested objects are merged field by field. Stops merge by
+// index, so {"stops": [{}, {"apptTime": "..."}]} re-times only the second
+// stop and a shorter stops array leaves the stops after it alone.
+type LoadPatch json.RawMessage
+
+// Check reports a *PatchError when the patch is not a load body, so a bad
+// patch can be refused before the load it applies to is fetched.
+func (p LoadPatch) Check() error {
+	_, err := p.Apply(CreateLoadRequest{})
+	return err
+}
+
+// Apply returns load with the patch merged over it, or a *PatchError when
+// the patch is not a load body.
+func (p LoadPatch) Apply(load CreateLoadRequest) (CreateLoadRequest, error) {
+	var patch struct {
+		Stops []json.RawMessage `json:"stops"`
+	}
+	if err := json.Unmarshal(p, &patch); err != nil {
+		return CreateLoadRequest{}, &PatchError{Err: err}
+	}
+
+	stops := load.Stops
+	load.Stops = nil
+	if err := json.Unmarshal(p, &load); err != nil {
+		return CreateLoadRequest{}, &PatchError{Err: err}
+	}
+	if patch.Stops == nil {
+		load.Stops = stops
+		return load, nil
+	}
+
+	// copy so the patch never writes through to the caller's stops
+	load.Stops = append([]CLStop(nil), stops...)
+	for i, raw := range patch.Stops {
+		if i == len(load.Stops) {
+			load.Stops = append(load.Stops, CLStop{})
+		}
+		if err := json.Unmarshal(raw, &load.Stops[i]); err != nil {
+			return CreateLoadRequest{}, &PatchError{Err: fmt.Errorf("stops[%d]: %w", i, err)}
+		}
+	}
+	return load, nil
+}
+
+// PatchError reports a load patch that is malformed or mistyped JSON.
+type PatchError struct {
+	Err error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("invalid load patch: %v", e.Err)
+}
+
+func (e *PatchError) Unwrap() error { return e.Err }
@@ -0,0 +1,32 @@
+package model
+
+import "strings"
+
+// StateCode returns the upper-case two-letter postal code of a US state
+// given by code or by full name, ignoring case and extra spaces.
+func StateCode(state string) (string, bool) {
+	s := strings.ToLower(strings.Join(strings.Fields(state), " "))
+	if code, ok := stateCodes[s]; ok {
+		return strings.ToUpper(code), true
+	}
+	for _, code := range stateCodes {
+		if code == s {
+			return strings.ToUpper(code), true
+		}
+	}
+	return "", false
+}
+
+var stateCodes = map[string]string{
+	"alabama": "al", "alaska": "ak", "arizona": "az", "arkansas": "ar", "california": "ca",
+	"colorado": "co", "connecticut": "ct", "delaware": "de", "district of columbia": "dc",
+	"florida": "fl", "georgia": "ga", "hawaii": "hi", "idaho": "id", "illinois": "il",
+	"indiana": "in", "iowa": "ia", "kansas": "ks", "kentucky": "ky", "louisiana": "la",
+	"maine": "me", "maryland": "md", "massachusetts": "ma", "michigan": "mi", "minnesota": "mn",
+	"mississippi": "ms", "missouri": "mo", "montana": "mt", "nebraska": "ne", "nevada": "nv",
+	"new hampshire": "nh", "new jersey": "nj", "new mexico": "nm", "new york": "ny",
+	"north carolina": "nc", "north dakota": "nd", "ohio": "oh", "oklahoma": "ok", "oregon": "or",
+	"pennsylvania": "pa", "rhode island": "ri", "south carolina": "sc", "south dakota": "sd",
+	"tennessee": "tn", "texas": "tx", "utah": "ut", "vermont": "vt", "virginia": "va",
+	"washington": "wa", "west virginia": "wv", "wisconsin": "wi", "wyoming": "wy",
+}
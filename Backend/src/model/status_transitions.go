@@ -0,0 +1,150 @@
+package model
+
+import "fmt"
+
+// statusNames are the display values Turvo uses for each status code.
+var statusNames = map[ShipmentStatusCode]string{
+	StatusQuoteActive:       "Quote active",
+	StatusTendered:          "Tendered",
+	StatusCovered:           "Covered",
+	StatusDispatched:        "Dispatched",
+	StatusAtPickup:          "At pickup",
+	StatusEnRoute:           "En route",
+	StatusAtDelivery:        "At delivery",
+	StatusDelivered:         "Delivered",
+	StatusReadyForBilling:   "Ready for billing",
+	StatusProcessing:        "Processing",
+	StatusCarrierPaid:       "Carrier paid",
+	StatusCustomerPaid:      "Customer paid",
+	StatusCompleted:         "Completed",
+	StatusCanceled:          "Canceled",
+	StatusQuoteInactive:     "Quote inactive",
+	StatusPickedUp:          "Picked up",
+	StatusRouteComplete:     "Route complete",
+	StatusTenderOffered:     "Tender offered",
+	StatusTenderAccepted:    "Tender accepted",
+	StatusTenderRejected:    "Tender rejected",
+	StatusDraft:             "Draft",
+	StatusShipmentReady:     "Shipment ready",
+	StatusAcquiringLocation: "Acquiring location",
+	StatusCustomsHold:       "Customs hold",
+	StatusArrived:           "Arrived",
+	StatusAvailable:         "Available",
+	StatusOutGated:          "Out gated",
+	StatusInGated:           "In gated",
+	StatusArrivingToPort:    "Arriving to port",
+	StatusBerthing:          "Berthing",
+	StatusUnloading:         "Unloading",
+	StatusRamped:            "Ramped",
+	StatusDeramped:          "Deramped",
+	StatusDeparted:          "Departed",
+	StatusHeld:              "Held",
+	StatusOutForDelivery:    "Out for delivery",
+	StatusInTransShipment:   "In trans shipment",
+	StatusOnHold:            "On hold",
+}
+
+// Name returns the Turvo display value for the status code, or the code
+// itself when it is unknown.
+func (c ShipmentStatusCode) Name() string {
+	if name, ok := statusNames[c]; ok {
+		return name
+	}
+	return string(c)
+}
+
+// inTransit are the movement and intermodal milestones between pickup and
+// delivery. A shipment may move freely between them.
+var inTransit = []ShipmentStatusCode{
+	StatusPickedUp, StatusEnRoute, StatusHeld, StatusInTransShipment,
+	StatusAcquiringLocation, StatusCustomsHold, StatusArrived, StatusAvailable,
+	StatusOutGated, StatusInGated, StatusArrivingToPort, StatusBerthing,
+	StatusUnloading, StatusRamped, StatusDeramped, StatusDeparted,
+}
+
+// statusTransitions is the legal transition graph. Statuses that are not keys
+// (Completed, Canceled) are terminal.
+var statusTransitions = map[ShipmentStatusCode][]ShipmentStatusCode{
+	StatusDraft:          {StatusQuoteActive, StatusTendered, StatusCovered, StatusCanceled},
+	StatusQuoteActive:    {StatusQuoteInactive, StatusTendered, StatusCovered, StatusCanceled},
+	StatusQuoteInactive:  {StatusQuoteActive, StatusCanceled},
+	StatusTendered:       {StatusTenderOffered, StatusCovered, StatusOnHold, StatusCanceled},
+	StatusTenderOffered:  {StatusTenderAccepted, StatusTenderRejected, StatusCanceled},
+	StatusTenderAccepted: {StatusCovered, StatusCanceled},
+	StatusTenderRejected: {StatusTendered, StatusTenderOffered, StatusCanceled},
+	StatusCovered:        {StatusTendered, StatusShipmentReady, StatusDispatched, StatusOnHold, StatusCanceled},
+	StatusShipmentReady:  {StatusDispatched, StatusOnHold, StatusCanceled},
+	StatusDispatched:     {StatusCovered, StatusAtPickup, StatusOnHold, StatusCanceled},
+	StatusAtPickup:       append([]ShipmentStatusCode{StatusOnHold}, inTransit...),
+	StatusOutForDelivery: {StatusAtDelivery, StatusDelivered, StatusOnHold},
+	StatusAtDelivery:     {StatusDelivered, StatusOnHold},
+	StatusOnHold: {
+		StatusTendered, StatusCovered, StatusDispatched, StatusAtPickup,
+		StatusPickedUp, StatusEnRoute, StatusAtDelivery, StatusCanceled,
+	},
+	StatusDelivered:       {StatusRouteComplete, StatusReadyForBilling, StatusCompleted},
+	StatusRouteComplete:   {StatusReadyForBilling, StatusCompleted},
+	StatusReadyForBilling: {StatusProcessing, StatusCarrierPaid, StatusCustomerPaid},
+	StatusProcessing:      {StatusCarrierPaid, StatusCustomerPaid},
+	StatusCarrierPaid:     {StatusCustomerPaid, StatusCompleted},
+	StatusCustomerPaid:    {StatusCarrierPaid, StatusCompleted},
+}
+
+func init() {
+	for _, from := range inTransit {
+		next := []ShipmentStatusCode{StatusOutForDelivery, StatusAtDelivery, StatusDelivered, StatusOnHold}
+		for _, to := range inTransit {
+			if to != from {
+				next = append(next, to)
+			}
+		}
+		statusTransitions[from] = next
+	}
+}
+
+// NextStatuses returns the statuses a shipment in status from may move to.
+func NextStatuses(from ShipmentStatusCode) []ShipmentStatusCode {
+	return append([]ShipmentStatusCode(nil), statusTransitions[from]...)
+}
+
+// TransitionError reports a status change the transition graph does not allow.
+type TransitionError struct {
+	From ShipmentStatusCode
+	To   ShipmentStatusCode
+}
+
+func (e *TransitionError) Error() string {
+	if e.From == e.To {
+		return fmt.Sprintf("load is already %s", e.From.Name())
+	}
+	return fmt.Sprintf("cannot move a load from %s to %s", e.From.Name(), e.To.Name())
+}
+
+// CheckTransition returns a *TransitionError unless a shipment may move from
+// status from to status to.
+func CheckTransition(from, to ShipmentStatusCode) error {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to}
+}
+
+// RequiresReason reports whether moving a shipment to the status needs a
+// reason note: canceling it or putting it on hold.
+func (c ShipmentStatusCode) RequiresReason() bool {
+	return c == StatusCanceled || c == StatusOnHold
+}
+
+// StatusChange moves a shipment to a new status. Reason is recorded as the
+// status note.
+type StatusChange struct {
+	Status ShipmentStatusCode
+	Reason string
+}
+
+// StatusUpdateRequest is the body of PUT /shipments/status/{id}.
+type StatusUpdateRequest struct {
+	Status AvroStatus `json:"status"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTransition(t *testing.T) {
+	allowed := [][2]ShipmentStatusCode{
+		{StatusTendered, StatusCovered},
+		{StatusCovered, StatusDispatched},
+		{StatusDispatched, StatusCanceled},
+		{StatusAtPickup, StatusEnRoute},
+		{StatusEnRoute, StatusCustomsHold},
+		{StatusCustomsHold, StatusEnRoute},
+		{StatusEnRoute, StatusDelivered},
+		{StatusOnHold, StatusDispatched},
+		{StatusDelivered, StatusReadyForBilling},
+		{StatusCustomerPaid, StatusCompleted},
+	}
+	for _, tr := range allowed {
+		assert.NoError(t, CheckTransition(tr[0], tr[1]), "%s -> %s", tr[0].Name(), tr[1].Name())
+	}
+
+	refused := [][2]ShipmentStatusCode{
+		{StatusDelivered, StatusTendered},
+		{StatusEnRoute, StatusCanceled},
+		{StatusCanceled, StatusTendered},
+		{StatusCompleted, StatusDelivered},
+		{StatusTendered, StatusDelivered},
+		{StatusCovered, StatusCovered},
+	}
+	for _, tr := range refused {
+		err := CheckTransition(tr[0], tr[1])
+		assert.Equal(t, &TransitionError{From: tr[0], To: tr[1]}, err, "%s -> %s", tr[0].Name(), tr[1].Name())
+	}
+}
+
+func TestTransitionError(t *testing.T) {
+	assert.EqualError(t, &TransitionError{From: StatusDelivered, To: StatusTendered}, "cannot move a load from Delivered to Tendered")
+	assert.EqualError(t, &TransitionError{From: StatusCovered, To: StatusCovered}, "load is already Covered")
+}
+
+func TestRequiresReason(t *testing.T) {
+	assert.True(t, StatusCanceled.RequiresReason())
+	assert.True(t, StatusOnHold.RequiresReason())
+	assert.False(t, StatusDispatched.RequiresReason())
+}
+
+func TestEveryStatusHasAName(t *testing.T) {
+	for _, code := range []ShipmentStatusCode{StatusQuoteActive, StatusOnHold, StatusInTransShipment} {
+		name := code.Name()
+		assert.NotEqual(t, string(code), name)
+		back, ok := StatusCodeForValue(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, code, back)
+	}
+	assert.Equal(t, "9999", ShipmentStatusCode("9999").Name())
+}
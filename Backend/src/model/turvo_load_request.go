@@ -50,6 +50,7 @@ type ContributorUser struct {
 }
 
 type Equipment struct {
+	ID             int      `json:"id,omitempty"`
 	Operation      int      `json:"_operation"`
 	Type           ValueKey `json:"type"`
 	Size           ValueKey `json:"size"`
@@ -164,6 +165,7 @@ type CustomerAvro struct {
 }
 
 type Item struct {
+	ID                   int                  `json:"id,omitempty"`
 	Dimensions           Dimensions           `json:"dimensions"`
 	ItemCategory         ValueKey             `json:"itemCategory"`
 	Qty                  int                  `json:"qty"`
@@ -225,6 +227,7 @@ type Costs struct {
 }
 
 type LineItem struct {
+	ID        int      `json:"id,omitempty"`
 	Code      ValueKey `json:"code"`
 	Qty       int      `json:"qty"`
 	Price     float64  `json:"price"`
@@ -235,15 +238,19 @@ type LineItem struct {
 }
 
 type ExternalID struct {
+	ID                 int      `json:"id,omitempty"`
+	Operation          int      `json:"_operation"`
 	Type               ValueKey `json:"type"`
 	Value              string   `json:"value"`
 	CopyToCarrierOrder bool     `json:"copyToCarrierOrder"`
 }
 
 type CarrierOrderAvro struct {
-	CarrierOrderSourceID int         `json:"carrierOrderSourceId"`
-	Carrier              CarrierAvro `json:"carrier"`
-	Drivers              []Driver    `json:"drivers"`
+	CarrierOrderSourceID int          `json:"carrierOrderSourceId"`
+	Carrier              CarrierAvro  `json:"carrier"`
+	Drivers              []Driver     `json:"drivers"`
+	Costs                Costs        `json:"costs"`
+	ExternalIds          []ExternalID `json:"externalIds,omitempty"`
 }
 
 type CarrierAvro struct {
@@ -252,7 +259,9 @@ type CarrierAvro struct {
 }
 
 type Driver struct {
-	DriverID        int `json:"driverId"`
-	Operation       int `json:"_operation"`
-	SegmentSequence int `json:"segmentSequence"`
+	DriverID        int    `json:"driverId,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Phone           string `json:"phone,omitempty"`
+	Operation       int    `json:"_operation"`
+	SegmentSequence int    `json:"segmentSequence"`
 }
@@ -0,0 +1,126 @@
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"drumkit.com/interview/src/model"
+)
+
+// Supplied profit figures may be rounded by the client this much.
+const (
+	profitUsdTolerance     = 0.01
+	profitPercentTolerance = 0.1
+)
+
+// Margin is what the broker keeps on a load: the customer linehaul less the
+// carrier linehaul, each rated flat, per mile or hourly. Fuel surcharge and
+// accessorials are passed through and do not count.
+type Margin struct {
+	CustomerLinehaul float64
+	CarrierLinehaul  float64
+	ProfitUsd        float64
+	ProfitPercent    float64
+}
+
+// MarginFor computes the margin of load. ok is false while either side is
+// not rated yet.
+func MarginFor(load model.CreateLoadRequest) (m Margin, ok bool, err error) {
+	items, err := CustomerLineItems(load.RateData, load.RouteMiles)
+	if err != nil {
+		return Margin{}, false, err
+	}
+	m.CustomerLinehaul = items[0].Amount
+	carrierItems, err := CarrierLineItems(load.RateData, load.RouteMiles)
+	if err != nil {
+		return Margin{}, false, err
+	}
+	m.CarrierLinehaul = Total(carrierItems)
+	if m.CustomerLinehaul == 0 || m.CarrierLinehaul == 0 {
+		return m, false, nil
+	}
+	m.ProfitUsd = cents(m.CustomerLinehaul - m.CarrierLinehaul)
+	m.ProfitPercent = m.ProfitUsd / m.CustomerLinehaul * 100
+	return m, true, nil
+}
+
+// MarginPolicy is the lowest margin a tenant books loads at.
+type MarginPolicy struct {
+	// FloorPercent is the lowest acceptable profit percent; zero means none.
+	FloorPercent float64
+	// Enforce rejects loads below the floor instead of flagging them.
+	Enforce bool
+}
+
+// MarginPolicyForTenant reads the policy from MARGIN_FLOOR_PERCENT and
+// MARGIN_FLOOR_ENFORCE, preferring the variables suffixed with the
+// upper-cased tenant, e.g. MARGIN_FLOOR_PERCENT_ACME_CO.
+func MarginPolicyForTenant(tenant string) (MarginPolicy, error) {
+	var policy MarginPolicy
+	if v := tenantEnv("MARGIN_FLOOR_PERCENT", tenant); v != "" {
+		floor, err := strconv.ParseFloat(v, 64)
+		if err != nil || floor < 0 || floor >= 100 {
+			return MarginPolicy{}, fmt.Errorf("margin floor %q is not a percent between 0 and 100", v)
+		}
+		policy.FloorPercent = floor
+	}
+	if v := tenantEnv("MARGIN_FLOOR_ENFORCE", tenant); v != "" {
+		enforce, err := strconv.ParseBool(v)
+		if err != nil {
+			return MarginPolicy{}, fmt.Errorf("margin floor enforcement %q is not a boolean", v)
+		}
+		policy.Enforce = enforce
+	}
+	return policy, nil
+}
+
+func tenantEnv(key, tenant string) string {
+	if tenant != "" {
+		if v := os.Getenv(key + "_" + strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(tenant))); v != "" {
+			return v
+		}
+	}
+	return os.Getenv(key)
+}
+
+// Check validates the rates of load against the policy. It rejects a carrier
+// linehaul above carrierMaxRate, supplied netProfitUsd or profitPercent figures
+// that disagree with the computed margin and, when enforced, a margin below
+// the floor. An unenforced floor is returned as a flag for review instead.
+func (p MarginPolicy) Check(load model.CreateLoadRequest) (flags []string, err error) {
+	rate := load.RateData
+	m, ok, err := MarginFor(load)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if rate.CarrierMaxRate > 0 && m.CarrierLinehaul > rate.CarrierMaxRate {
+		problems = append(problems, fmt.Sprintf("carrier linehaul %.2f exceeds carrierMaxRate %.2f", m.CarrierLinehaul, rate.CarrierMaxRate))
+	}
+	if ok {
+		if rate.NetProfitUsd != 0 && math.Abs(rate.NetProfitUsd-m.ProfitUsd) > profitUsdTolerance {
+			problems = append(problems, fmt.Sprintf("netProfitUsd %.2f does not match the computed %.2f", rate.NetProfitUsd, m.ProfitUsd))
+		}
+		if rate.ProfitPercent != 0 && math.Abs(rate.ProfitPercent-m.ProfitPercent) > profitPercentTolerance {
+			problems = append(problems, fmt.Sprintf("profitPercent %.1f does not match the computed %.1f", rate.ProfitPercent, m.ProfitPercent))
+		}
+		if p.FloorPercent > 0 && m.ProfitPercent < p.FloorPercent {
+			below := fmt.Sprintf("margin %.1f%% is below the %.1f%% floor", m.ProfitPercent, p.FloorPercent)
+			if p.Enforce {
+				problems = append(problems, below)
+			} else {
+				flags = append(flags, below)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return flags, nil
+}
@@ -0,0 +1,83 @@
+package rating
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarginFor(t *testing.T) {
+	m, ok, err := MarginFor(model.CreateLoadRequest{
+		RouteMiles: 800,
+		RateData:   model.CLRateData{CustomerRateType: TypePerMile, CustomerLhRateUsd: 2.5, FscPercent: 10, CarrierLhRateUsd: 1700},
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, Margin{CustomerLinehaul: 2000, CarrierLinehaul: 1700, ProfitUsd: 300, ProfitPercent: 15}, m)
+
+	m, ok, err = MarginFor(model.CreateLoadRequest{
+		RateData: model.CLRateData{CustomerLhRateUsd: 1000, CarrierRateType: TypeHourly, CarrierLhRateUsd: 50, CarrierNumHours: 10},
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, Margin{CustomerLinehaul: 1000, CarrierLinehaul: 500, ProfitUsd: 500, ProfitPercent: 50}, m)
+
+	_, ok, err = MarginFor(model.CreateLoadRequest{RateData: model.CLRateData{CustomerLhRateUsd: 2000}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMarginPolicy_Check(t *testing.T) {
+	rated := func(customer, carrier float64) model.CLRateData {
+		return model.CLRateData{CustomerLhRateUsd: customer, CarrierLhRateUsd: carrier}
+	}
+	for name, tc := range map[string]struct {
+		policy    MarginPolicy
+		rate      model.CLRateData
+		wantErr   string
+		wantFlags int
+	}{
+		"healthy":          {MarginPolicy{FloorPercent: 10}, rated(2000, 1700), "", 0},
+		"no carrier yet":   {MarginPolicy{FloorPercent: 10}, rated(2000, 0), "", 0},
+		"matching figures": {MarginPolicy{}, model.CLRateData{CustomerLhRateUsd: 3000, CarrierLhRateUsd: 2000, NetProfitUsd: 1000, ProfitPercent: 33.3}, "", 0},
+		"below floor":      {MarginPolicy{FloorPercent: 20}, rated(2000, 1700), "", 1},
+		"below enforced":   {MarginPolicy{FloorPercent: 20, Enforce: true}, rated(2000, 1700), "margin 15.0% is below the 20.0% floor", 0},
+		"over max rate":    {MarginPolicy{}, model.CLRateData{CustomerLhRateUsd: 2000, CarrierLhRateUsd: 1700, CarrierMaxRate: 1600}, "exceeds carrierMaxRate 1600.00", 0},
+		"wrong profit":     {MarginPolicy{}, model.CLRateData{CustomerLhRateUsd: 2000, CarrierLhRateUsd: 1700, NetProfitUsd: 350}, "netProfitUsd 350.00 does not match the computed 300.00", 0},
+		"wrong percent":    {MarginPolicy{}, model.CLRateData{CustomerLhRateUsd: 2000, CarrierLhRateUsd: 1700, ProfitPercent: 17.5}, "profitPercent 17.5 does not match the computed 15.0", 0},
+		"invalid rate":     {MarginPolicy{}, model.CLRateData{CustomerRateType: "weekly"}, "customerRateType: unknown rate type", 0},
+		"hourly carrier":   {MarginPolicy{FloorPercent: 40, Enforce: true}, model.CLRateData{CustomerLhRateUsd: 1000, CarrierRateType: TypeHourly, CarrierLhRateUsd: 50, CarrierNumHours: 10, CarrierMaxRate: 600, NetProfitUsd: 500}, "", 0},
+		"hourly over max":  {MarginPolicy{}, model.CLRateData{CustomerLhRateUsd: 1000, CarrierRateType: TypeHourly, CarrierLhRateUsd: 50, CarrierNumHours: 10, CarrierMaxRate: 400}, "carrier linehaul 500.00 exceeds carrierMaxRate 400.00", 0},
+		"negative margin":  {MarginPolicy{FloorPercent: 5, Enforce: true}, rated(1500, 1700), "below the 5.0% floor", 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			flags, err := tc.policy.Check(model.CreateLoadRequest{RateData: tc.rate})
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, flags, tc.wantFlags)
+		})
+	}
+}
+
+func TestMarginPolicyForTenant(t *testing.T) {
+	t.Setenv("MARGIN_FLOOR_PERCENT", "8")
+	t.Setenv("MARGIN_FLOOR_PERCENT_ACME_CO", "12.5")
+	t.Setenv("MARGIN_FLOOR_ENFORCE_ACME_CO", "true")
+
+	policy, err := MarginPolicyForTenant("acme-co")
+	require.NoError(t, err)
+	assert.Equal(t, MarginPolicy{FloorPercent: 12.5, Enforce: true}, policy)
+
+	policy, err = MarginPolicyForTenant("other")
+	require.NoError(t, err)
+	assert.Equal(t, MarginPolicy{FloorPercent: 8}, policy)
+
+	t.Setenv("MARGIN_FLOOR_PERCENT", "lots")
+	_, err = MarginPolicyForTenant("other")
+	assert.Error(t, err)
+}
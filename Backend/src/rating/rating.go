@@ -0,0 +1,252 @@
+// Package rating turns a load's customer and carrier rates into Turvo cost
+// line items and reads the customer rate back from them. Either linehaul is
+// rated flat, per mile over routeMiles or hourly, and the customer may add a
+// fuel surcharge by percent of linehaul or per mile. The margin between the
+// two is checked against carrierMaxRate, any profit figures the client sent
+// and the tenant's margin floor.
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/model"
+)
+
+// Rate types accepted in CLRateData.CustomerRateType and CarrierRateType. An
+// empty type is flat.
+const (
+	TypeFlat    = "flat"
+	TypePerMile = "perMile"
+	TypeHourly  = "hourly"
+)
+
+// linehaulCodes are the tenant's line item codes by rate type. Fuel lines are
+// read back by their own codes, so fuel lines entered by hand in Turvo under
+// another code are left alone.
+func linehaulCodes() map[string]model.ValueKey {
+	codes := lookup.Current().LineItems
+	return map[string]model.ValueKey{
+		TypeFlat:    codes.FreightFlat,
+		TypePerMile: codes.FreightPerMile,
+		TypeHourly:  codes.FreightHourly,
+	}
+}
+
+// ParseType returns the rate type named by s, accepting common spellings such
+// as "Per Mile" or "hour".
+func ParseType(s string) (string, error) {
+	switch strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(s)) {
+	case "", "flat", "flatrate":
+		return TypeFlat, nil
+	case "permile", "mile", "mileage":
+		return TypePerMile, nil
+	case "hourly", "hour", "perhour":
+		return TypeHourly, nil
+	}
+	return "", fmt.Errorf("unknown rate type %q; use flat, perMile or hourly", s)
+}
+
+// CustomerLineItems returns the billable linehaul and fuel surcharge line items
+// for rate, in that order. Per-mile rates and fuel surcharges are charged over
+// routeMiles.
+func CustomerLineItems(rate model.CLRateData, routeMiles float64) ([]model.LineItem, error) {
+	rateType, err := ParseType(rate.CustomerRateType)
+	if err != nil {
+		return nil, fmt.Errorf("customerRateType: %w", err)
+	}
+	if err := validate(rate, rateType, routeMiles); err != nil {
+		return nil, err
+	}
+
+	line := linehaul(rateType, rate.CustomerLhRateUsd, rate.CustomerNumHours, routeMiles)
+	line.Billable = true
+	items := []model.LineItem{line}
+
+	codes := lookup.Current().LineItems
+	fuel := model.LineItem{Qty: 1, Billable: true}
+	switch {
+	case rate.FscPercent > 0:
+		fuel.Code = codes.FuelPercent
+		fuel.Amount = cents(line.Amount * rate.FscPercent / 100)
+		fuel.Price = fuel.Amount
+	case rate.FscPerMile > 0:
+		fuel.Code = codes.FuelPerMile
+		fuel.Qty = quantity(routeMiles)
+		fuel.Price = rate.FscPerMile
+		fuel.Amount = cents(rate.FscPerMile * routeMiles)
+	default:
+		return items, nil
+	}
+	return append(items, fuel), nil
+}
+
+// CarrierLineItems returns the linehaul line item paid to the carrier for
+// rate, or none while the carrier is not rated. Per-mile rates are paid over
+// routeMiles.
+func CarrierLineItems(rate model.CLRateData, routeMiles float64) ([]model.LineItem, error) {
+	rateType, err := ParseType(rate.CarrierRateType)
+	if err != nil {
+		return nil, fmt.Errorf("carrierRateType: %w", err)
+	}
+	if rate.CarrierLhRateUsd == 0 {
+		return []model.LineItem{}, nil
+	}
+
+	var problems []string
+	if rate.CarrierLhRateUsd < 0 {
+		problems = append(problems, "carrierLhRateUsd cannot be negative")
+	}
+	if rateType == TypePerMile && routeMiles <= 0 {
+		problems = append(problems, "routeMiles is required for per-mile rates")
+	}
+	if rateType == TypeHourly && rate.CarrierNumHours <= 0 {
+		problems = append(problems, "carrierNumHours is required for hourly rates")
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return []model.LineItem{linehaul(rateType, rate.CarrierLhRateUsd, rate.CarrierNumHours, routeMiles)}, nil
+}
+
+// linehaul is the linehaul line item for rateUsd charged flat, per hour over
+// numHours or per mile over routeMiles.
+func linehaul(rateType string, rateUsd, numHours, routeMiles float64) model.LineItem {
+	line := model.LineItem{Code: linehaulCodes()[rateType], Qty: 1, Price: rateUsd}
+	switch rateType {
+	case TypeFlat:
+		line.Amount = rateUsd
+	case TypePerMile:
+		line.Qty = quantity(routeMiles)
+		line.Amount = cents(rateUsd * routeMiles)
+	case TypeHourly:
+		line.Qty = quantity(numHours)
+		line.Amount = cents(rateUsd * numHours)
+	}
+	return line
+}
+
+func validate(rate model.CLRateData, rateType string, routeMiles float64) error {
+	var problems []string
+	if rate.CustomerLhRateUsd < 0 {
+		problems = append(problems, "customerLhRateUsd cannot be negative")
+	}
+	if rate.FscPercent < 0 || rate.FscPerMile < 0 {
+		problems = append(problems, "fuel surcharge cannot be negative")
+	}
+	if rate.FscPercent > 0 && rate.FscPerMile > 0 {
+		problems = append(problems, "give fscPercent or fscPerMile, not both")
+	}
+	if routeMiles <= 0 && (rateType == TypePerMile || rate.FscPerMile > 0) {
+		problems = append(problems, "routeMiles is required for per-mile rates")
+	}
+	if rateType == TypeHourly && rate.CustomerNumHours <= 0 {
+		problems = append(problems, "customerNumHours is required for hourly rates")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Rate is a customer rate read back from line items.
+type Rate struct {
+	Type       string
+	LhRateUsd  float64
+	NumHours   float64
+	FscPercent float64
+	FscPerMile float64
+	RouteMiles float64
+	// Linehaul is the amount charged for the linehaul itself.
+	Linehaul float64
+}
+
+// FromCosts reads the customer rate back from costs. Without a linehaul line
+// item the whole total is taken as a flat linehaul.
+func FromCosts(costs model.Costs) Rate {
+	var rate Rate
+	found := false
+	for _, li := range costs.LineItem {
+		rateType, ok := LinehaulType(li)
+		if !ok {
+			continue
+		}
+		found = true
+		rate.Type = rateType
+		rate.Linehaul = li.Amount
+		rate.LhRateUsd = li.Amount
+		if rateType != TypeFlat && li.Price != 0 {
+			rate.LhRateUsd = li.Price
+			units := li.Amount / li.Price
+			if rateType == TypePerMile {
+				rate.RouteMiles = units
+			} else {
+				rate.NumHours = units
+			}
+		}
+		break
+	}
+	if !found {
+		rate.Type = TypeFlat
+		rate.Linehaul = costs.TotalAmount
+		rate.LhRateUsd = costs.TotalAmount
+	}
+
+	codes := lookup.Current().LineItems
+	for _, li := range costs.LineItem {
+		switch li.Code.Key {
+		case codes.FuelPercent.Key:
+			if rate.Linehaul != 0 {
+				rate.FscPercent = math.Round(li.Amount/rate.Linehaul*100*1000) / 1000
+			}
+		case codes.FuelPerMile.Key:
+			rate.FscPerMile = li.Price
+			if rate.RouteMiles == 0 && li.Price != 0 {
+				rate.RouteMiles = li.Amount / li.Price
+			}
+		}
+	}
+	return rate
+}
+
+// LinehaulType reports the rate type li bills the linehaul under.
+func LinehaulType(li model.LineItem) (string, bool) {
+	for rateType, code := range linehaulCodes() {
+		if li.Code.Key == code.Key {
+			return rateType, true
+		}
+	}
+	return "", false
+}
+
+// IsFuelSurcharge reports whether li is charged under one of the fuel
+// surcharge codes CustomerLineItems writes.
+func IsFuelSurcharge(li model.LineItem) bool {
+	codes := lookup.Current().LineItems
+	return li.Code.Key == codes.FuelPercent.Key || li.Code.Key == codes.FuelPerMile.Key
+}
+
+// Total sums the amounts of items.
+func Total(items []model.LineItem) float64 {
+	var total float64
+	for _, li := range items {
+		total += li.Amount
+	}
+	return cents(total)
+}
+
+func cents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// quantity is the whole number of units shown on a line item; the amount is
+// always computed from the exact figure.
+func quantity(units float64) int {
+	if q := int(math.Round(units)); q > 0 {
+		return q
+	}
+	return 1
+}
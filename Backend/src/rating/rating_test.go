@@ -0,0 +1,137 @@
+package rating
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/lookup"
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerLineItems(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rate       model.CLRateData
+		miles      float64
+		wantCodes  []string
+		wantAmount []float64
+	}{
+		"flat":              {model.CLRateData{CustomerLhRateUsd: 1800}, 0, []string{"1600"}, []float64{1800}},
+		"flat with fsc %":   {model.CLRateData{CustomerRateType: "Flat", CustomerLhRateUsd: 1800, FscPercent: 15}, 0, []string{"1600", "1604"}, []float64{1800, 270}},
+		"per mile":          {model.CLRateData{CustomerRateType: "perMile", CustomerLhRateUsd: 2.15}, 640, []string{"1602"}, []float64{1376}},
+		"per mile fsc":      {model.CLRateData{CustomerRateType: "mile", CustomerLhRateUsd: 2, FscPerMile: 0.42}, 333.3, []string{"1602", "1605"}, []float64{666.6, 139.99}},
+		"hourly":            {model.CLRateData{CustomerRateType: "hourly", CustomerLhRateUsd: 85, CustomerNumHours: 6.5}, 0, []string{"1603"}, []float64{552.5}},
+		"hourly with fsc %": {model.CLRateData{CustomerRateType: "per hour", CustomerLhRateUsd: 80, CustomerNumHours: 4, FscPercent: 10}, 0, []string{"1603", "1604"}, []float64{320, 32}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			items, err := CustomerLineItems(tc.rate, tc.miles)
+			require.NoError(t, err)
+			var codes []string
+			var amounts []float64
+			for _, li := range items {
+				codes = append(codes, li.Code.Key)
+				amounts = append(amounts, li.Amount)
+				assert.True(t, li.Billable)
+			}
+			assert.Equal(t, tc.wantCodes, codes)
+			assert.Equal(t, tc.wantAmount, amounts)
+		})
+	}
+}
+
+func TestCarrierLineItems(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rate  model.CLRateData
+		miles float64
+		want  []model.LineItem
+	}{
+		"unrated":  {model.CLRateData{CarrierRateType: "hourly"}, 0, []model.LineItem{}},
+		"flat":     {model.CLRateData{CarrierLhRateUsd: 1200}, 0, []model.LineItem{{Code: linehaulCodes()[TypeFlat], Qty: 1, Price: 1200, Amount: 1200}}},
+		"per mile": {model.CLRateData{CarrierRateType: "per mile", CarrierLhRateUsd: 1.9}, 640, []model.LineItem{{Code: linehaulCodes()[TypePerMile], Qty: 640, Price: 1.9, Amount: 1216}}},
+		"hourly":   {model.CLRateData{CarrierRateType: "hourly", CarrierLhRateUsd: 50, CarrierNumHours: 10}, 0, []model.LineItem{{Code: linehaulCodes()[TypeHourly], Qty: 10, Price: 50, Amount: 500}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			items, err := CarrierLineItems(tc.rate, tc.miles)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, items)
+		})
+	}
+
+	_, err := CarrierLineItems(model.CLRateData{CarrierRateType: "weekly"}, 0)
+	assert.ErrorContains(t, err, "carrierRateType: unknown rate type")
+	_, err = CarrierLineItems(model.CLRateData{CarrierRateType: "hourly", CarrierLhRateUsd: 50}, 0)
+	assert.ErrorContains(t, err, "carrierNumHours is required")
+	_, err = CarrierLineItems(model.CLRateData{CarrierRateType: "perMile", CarrierLhRateUsd: 2}, 0)
+	assert.ErrorContains(t, err, "routeMiles is required")
+}
+
+func TestCustomerLineItems_Rejects(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rate  model.CLRateData
+		miles float64
+		want  string
+	}{
+		"unknown type":      {model.CLRateData{CustomerRateType: "weekly"}, 0, "customerRateType: unknown rate type"},
+		"negative rate":     {model.CLRateData{CustomerLhRateUsd: -1}, 0, "cannot be negative"},
+		"both surcharges":   {model.CLRateData{FscPercent: 10, FscPerMile: 0.3}, 100, "not both"},
+		"per mile no miles": {model.CLRateData{CustomerRateType: "perMile", CustomerLhRateUsd: 2}, 0, "routeMiles is required"},
+		"fsc no miles":      {model.CLRateData{CustomerLhRateUsd: 900, FscPerMile: 0.3}, 0, "routeMiles is required"},
+		"hourly no hours":   {model.CLRateData{CustomerRateType: "hourly", CustomerLhRateUsd: 80}, 0, "customerNumHours is required"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := CustomerLineItems(tc.rate, tc.miles)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestFromCosts(t *testing.T) {
+	for name, tc := range map[string]struct {
+		rate  model.CLRateData
+		miles float64
+	}{
+		"flat percent":      {model.CLRateData{CustomerRateType: TypeFlat, CustomerLhRateUsd: 1800, FscPercent: 15}, 0},
+		"per mile per mile": {model.CLRateData{CustomerRateType: TypePerMile, CustomerLhRateUsd: 2, FscPerMile: 0.5}, 400},
+		"hourly":            {model.CLRateData{CustomerRateType: TypeHourly, CustomerLhRateUsd: 85, CustomerNumHours: 6.5}, 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			items, err := CustomerLineItems(tc.rate, tc.miles)
+			require.NoError(t, err)
+
+			rate := FromCosts(model.Costs{TotalAmount: Total(items), LineItem: items})
+			assert.Equal(t, tc.rate.CustomerRateType, rate.Type)
+			assert.Equal(t, tc.rate.CustomerLhRateUsd, rate.LhRateUsd)
+			assert.Equal(t, tc.rate.CustomerNumHours, rate.NumHours)
+			assert.Equal(t, tc.rate.FscPercent, rate.FscPercent)
+			assert.Equal(t, tc.rate.FscPerMile, rate.FscPerMile)
+			assert.Equal(t, tc.miles, rate.RouteMiles)
+		})
+	}
+}
+
+func TestFromCosts_KeepsUnknownFuelLines(t *testing.T) {
+	rate := FromCosts(model.Costs{TotalAmount: 2100, LineItem: []model.LineItem{
+		{Code: model.ValueKey{Key: "1600"}, Amount: 2000},
+		{Code: model.ValueKey{Key: "1601", Value: "Fuel"}, Amount: 100, Notes: "Fuel surcharge - percent of linehaul"},
+	}})
+	assert.Equal(t, Rate{Type: TypeFlat, LhRateUsd: 2000, Linehaul: 2000}, rate)
+
+	assert.Equal(t, Rate{Type: TypeFlat, LhRateUsd: 950, Linehaul: 950}, FromCosts(model.Costs{TotalAmount: 950}))
+}
+
+func TestCustomerLineItems_TenantCodes(t *testing.T) {
+	keys := lookup.Default()
+	keys.LineItems.FreightFlat = model.ValueKey{Key: "2600", Value: "Linehaul"}
+	keys.LineItems.FuelPercent = model.ValueKey{Key: "2601", Value: "FSC"}
+	lookup.Use(keys)
+	t.Cleanup(func() { lookup.Use(lookup.Default()) })
+
+	items, err := CustomerLineItems(model.CLRateData{CustomerLhRateUsd: 1000, FscPercent: 10}, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, keys.LineItems.FreightFlat, items[0].Code)
+	assert.Equal(t, keys.LineItems.FuelPercent, items[1].Code)
+
+	rate := FromCosts(model.Costs{TotalAmount: Total(items), LineItem: items})
+	assert.Equal(t, Rate{Type: TypeFlat, LhRateUsd: 1000, FscPercent: 10, Linehaul: 1000}, rate)
+}
@@ -0,0 +1,191 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// Hours are the weekly opening hours of a location. The zero value has no
+// hours and is open at any time.
+type Hours struct {
+	// open holds the open spans of each weekday; a span closing past midnight
+	// runs into the next day.
+	open [7][]span
+	set  bool
+}
+
+// span is an open interval in minutes after midnight.
+type span struct{ from, to int }
+
+var (
+	dayPattern   = `(?:mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?`
+	clockPattern = `\d{1,2}(?::\d{2})?\s*(?:[ap]\.?m\.?)?`
+	hoursGroup   = regexp.MustCompile(`(?i)(?:(` + dayPattern + `(?:\s*(?:-|–|to|,|&|/|and)\s*` + dayPattern + `)*)\s*:?\s*)?` +
+		`(?:(` + clockPattern + `)\s*(?:-|–|to)\s*(` + clockPattern + `)|(closed))`)
+	dayName   = regexp.MustCompile(`(?i)` + dayPattern)
+	allHours  = regexp.MustCompile(`(?i)^\s*(?:24/7|24 ?hours|open 24 hours|always open)\s*$`)
+	leftovers = regexp.MustCompile(`^[\s;,|]*$`)
+	clock     = regexp.MustCompile(`(?i)^(\d{1,2})(?::(\d{2}))?\s*(?:([ap])\.?m\.?)?$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseHours reads opening hours such as "Mon-Fri 07:00-15:00",
+// "Mon-Fri 7am-3pm; Sat 8am-12pm; Sun closed" or "24/7". Hours given without
+// days apply to every day. An empty string gives the zero Hours.
+func ParseHours(s string) (Hours, error) {
+	var h Hours
+	if strings.TrimSpace(s) == "" {
+		return h, nil
+	}
+	h.set = true
+	if allHours.MatchString(s) {
+		for d := range h.open {
+			h.open[d] = []span{{0, minutesPerDay}}
+		}
+		return h, nil
+	}
+
+	matches := hoursGroup.FindAllStringSubmatchIndex(s, -1)
+	rest := s
+	for i := len(matches) - 1; i >= 0; i-- {
+		rest = rest[:matches[i][0]] + " " + rest[matches[i][1]:]
+	}
+	if len(matches) == 0 || !leftovers.MatchString(rest) {
+		return Hours{}, fmt.Errorf("cannot read business hours %q; use a form like \"Mon-Fri 07:00-15:00\"", s)
+	}
+
+	for _, m := range matches {
+		group := func(n int) string {
+			if m[2*n] < 0 {
+				return ""
+			}
+			return s[m[2*n]:m[2*n+1]]
+		}
+		days := allWeekdays()
+		if list := group(1); list != "" {
+			days = parseDays(list)
+		}
+		if group(4) != "" {
+			for _, d := range days {
+				h.open[d] = nil
+			}
+			continue
+		}
+		from, err := parseClock(group(2))
+		if err != nil {
+			return Hours{}, fmt.Errorf("business hours %q: %w", s, err)
+		}
+		to, err := parseClock(group(3))
+		if err != nil {
+			return Hours{}, fmt.Errorf("business hours %q: %w", s, err)
+		}
+		if to <= from {
+			to += minutesPerDay
+		}
+		for _, d := range days {
+			h.open[d] = append(h.open[d], span{from, to})
+		}
+	}
+	return h, nil
+}
+
+// IsZero reports whether h has no hours and so never closes.
+func (h Hours) IsZero() bool {
+	return !h.set
+}
+
+// Open reports whether the location is open at t, read in t's zone.
+func (h Hours) Open(t time.Time) bool {
+	return h.Overlaps(t, t)
+}
+
+// Overlaps reports whether the location is open at any time from from to to,
+// read in from's zone.
+func (h Hours) Overlaps(from, to time.Time) bool {
+	if !h.set {
+		return true
+	}
+	loc := from.Location()
+	to = to.In(loc)
+	// start a day early for spans running past midnight
+	day := time.Date(from.Year(), from.Month(), from.Day()-1, 0, 0, 0, 0, loc)
+	for !day.After(to) {
+		for _, sp := range h.open[day.Weekday()] {
+			// wall-clock times, so spans keep their hours across DST changes
+			open := time.Date(day.Year(), day.Month(), day.Day(), 0, sp.from, 0, 0, loc)
+			closes := time.Date(day.Year(), day.Month(), day.Day(), 0, sp.to, 0, 0, loc)
+			if !open.After(to) && !closes.Before(from) {
+				return true
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+	}
+	return false
+}
+
+// parseDays returns the weekdays of a list such as "Mon-Fri", "Mon, Wed & Fri"
+// or "Fri-Mon".
+func parseDays(list string) []time.Weekday {
+	names := dayName.FindAllStringIndex(list, -1)
+	var days []time.Weekday
+	for i, n := range names {
+		d := weekdays[strings.ToLower(list[n[0]:n[0]+3])]
+		if i > 0 {
+			sep := strings.ToLower(strings.TrimSpace(list[names[i-1][1]:n[0]]))
+			if sep == "-" || sep == "–" || sep == "to" {
+				for prev := days[len(days)-1]; prev != d; {
+					prev = (prev + 1) % 7
+					days = append(days, prev)
+				}
+				continue
+			}
+		}
+		days = append(days, d)
+	}
+	return days
+}
+
+func allWeekdays() []time.Weekday {
+	return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+}
+
+// parseClock returns the minutes after midnight of a time such as "07:00",
+// "7am" or "3:30 pm". "24:00" closes at midnight.
+func parseClock(s string) (int, error) {
+	m := clock.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("cannot read time %q", s)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if minute > 59 {
+		return 0, fmt.Errorf("cannot read time %q", s)
+	}
+	switch strings.ToLower(m[3]) {
+	case "":
+		if hour > 24 || (hour == 24 && minute > 0) {
+			return 0, fmt.Errorf("cannot read time %q", s)
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return 0, fmt.Errorf("cannot read time %q", s)
+		}
+		hour %= 12
+		if strings.EqualFold(m[3], "p") {
+			hour += 12
+		}
+	}
+	return hour*60 + minute, nil
+}
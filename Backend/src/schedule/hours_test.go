@@ -0,0 +1,128 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHours(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// 2025-08-04 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 8, 3+day, hour, minute, 0, 0, chicago)
+	}
+
+	for name, tc := range map[string]struct {
+		hours  string
+		open   []time.Time
+		closed []time.Time
+	}{
+		"weekdays": {
+			hours:  "Mon-Fri 07:00-15:00",
+			open:   []time.Time{at(1, 7, 0), at(5, 15, 0)},
+			closed: []time.Time{at(1, 6, 59), at(3, 15, 1), at(6, 9, 0), at(0, 9, 0)},
+		},
+		"twelve hour groups": {
+			hours:  "Mon-Fri 7am-3pm; Sat 8:30 a.m. - 12 pm; Sun closed",
+			open:   []time.Time{at(2, 14, 0), at(6, 8, 30)},
+			closed: []time.Time{at(6, 13, 0), at(0, 10, 0)},
+		},
+		"day list": {
+			hours:  "Mon, Wed & Fri: 08:00-12:00",
+			open:   []time.Time{at(3, 9, 0)},
+			closed: []time.Time{at(2, 9, 0)},
+		},
+		"no days": {
+			hours:  "06:00-18:00",
+			open:   []time.Time{at(0, 6, 0), at(6, 17, 0)},
+			closed: []time.Time{at(3, 19, 0)},
+		},
+		"overnight": {
+			hours:  "Fri 22:00-06:00",
+			open:   []time.Time{at(5, 23, 0), at(6, 5, 0)},
+			closed: []time.Time{at(5, 7, 0), at(6, 7, 0)},
+		},
+		"wrapping days": {
+			hours:  "Fri-Mon 09:00-17:00",
+			open:   []time.Time{at(0, 9, 0), at(1, 9, 0)},
+			closed: []time.Time{at(2, 9, 0)},
+		},
+		"always": {
+			hours: "24/7",
+			open:  []time.Time{at(0, 3, 0), at(4, 23, 59)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			h, err := ParseHours(tc.hours)
+			require.NoError(t, err)
+			for _, when := range tc.open {
+				assert.True(t, h.Open(when), when.Format(time.RFC1123))
+			}
+			for _, when := range tc.closed {
+				assert.False(t, h.Open(when), when.Format(time.RFC1123))
+			}
+		})
+	}
+
+	h, err := ParseHours("  ")
+	require.NoError(t, err)
+	assert.True(t, h.IsZero())
+	assert.True(t, h.Open(at(0, 3, 0)))
+}
+
+func TestParseHours_Rejects(t *testing.T) {
+	for _, hours := range []string{"string", "Mon-Fri", "Mon-Fri 07:00-15:00 except holidays", "Mon 25:00-26:00", "Mon 13pm-2pm"} {
+		_, err := ParseHours(hours)
+		assert.Error(t, err, hours)
+	}
+}
+
+func TestHoursOverlaps(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	h, err := ParseHours("Mon-Fri 07:00-15:00")
+	require.NoError(t, err)
+
+	friday := time.Date(2025, 8, 8, 16, 0, 0, 0, chicago)
+	assert.False(t, h.Overlaps(friday, friday.Add(60*time.Hour)), "closed for the weekend")
+	assert.True(t, h.Overlaps(friday, friday.Add(64*time.Hour)), "opens Monday at 07:00")
+	assert.True(t, h.Overlaps(friday.Add(-2*time.Hour), friday), "open until 15:00")
+}
+
+func TestForStop_BusinessHours(t *testing.T) {
+	stop := func(ready, appt string) model.CLStop {
+		return model.CLStop{Type: model.StopTypePickup, State: "IL", BusinessHours: "Mon-Fri 07:00-15:00", ReadyTime: ready, ApptTime: appt}
+	}
+
+	_, err := ForStop(stop("", "2025-08-04T09:00"))
+	assert.NoError(t, err)
+	_, err = ForStop(stop("2025-08-02T08:00", "2025-08-04T07:30"))
+	assert.NoError(t, err, "the window reaches Monday morning")
+
+	_, err = ForStop(stop("", "2025-08-04T16:00"))
+	assert.ErrorContains(t, err, `apptTime 2025-08-04T16:00:00-05:00 is outside businessHours "Mon-Fri 07:00-15:00"`)
+	_, err = ForStop(stop("2025-08-02T08:00", "2025-08-03T12:00"))
+	assert.ErrorContains(t, err, "apptTime window 2025-08-02T08:00:00-05:00 to 2025-08-03T12:00:00-05:00 is outside businessHours")
+	for _, free := range []string{"string", "24/7 call ahead"} {
+		_, err = ForStop(model.CLStop{Type: model.StopTypePickup, BusinessHours: free, ApptTime: "2025-08-02T23:00"})
+		assert.NoError(t, err, "hours that cannot be parsed are not checked: %s", free)
+	}
+}
+
+func TestHours_KeepWallClockAcrossDST(t *testing.T) {
+	h, err := ParseHours("Sun 07:00-15:00")
+	require.NoError(t, err)
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	// clocks spring forward on 2025-03-09 and fall back on 2025-11-02
+	assert.True(t, h.Open(time.Date(2025, 3, 9, 7, 30, 0, 0, chicago)))
+	assert.False(t, h.Open(time.Date(2025, 3, 9, 15, 30, 0, 0, chicago)))
+	assert.True(t, h.Open(time.Date(2025, 11, 2, 14, 30, 0, 0, chicago)))
+	assert.False(t, h.Open(time.Date(2025, 11, 2, 6, 30, 0, 0, chicago)))
+}
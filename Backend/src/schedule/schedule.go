@@ -0,0 +1,184 @@
+// Package schedule normalizes stop times: it finds the time zone each stop
+// keeps, from its timezone or else its state and zip, and reads the stop's
+// appointment and window in it. Times outside the stop's business hours, given
+// as e.g. "Mon-Fri 07:00-15:00; Sat 8am-12pm", are refused.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Lambda images do not always ship a zone database.
+	_ "time/tzdata"
+
+	"drumkit.com/interview/src/model"
+)
+
+// DefaultZone is used for stops that neither name a zone nor have a state it
+// can be inferred from.
+const DefaultZone = "America/Chicago"
+
+// localLayouts are the accepted forms of a time without an offset, which is
+// read in the stop's zone.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+const dateLayout = "2006-01-02"
+
+// Zone returns the zone of stop: its IANA timezone when set, otherwise the
+// zone of its state, refined by zip code where a state spans two zones.
+func Zone(stop model.CLStop) (*time.Location, error) {
+	if name := strings.TrimSpace(stop.Timezone); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil || name == "Local" {
+			return nil, fmt.Errorf("unknown timezone %q", name)
+		}
+		return loc, nil
+	}
+
+	state := strings.ToUpper(strings.TrimSpace(stop.State))
+	if code, ok := model.StateCode(stop.State); ok {
+		state = code
+	}
+	name, ok := zoneFor(state, stop.Zipcode)
+	if !ok {
+		name = DefaultZone
+	}
+	return time.LoadLocation(name)
+}
+
+// ParseTime reads value in loc. Times with an offset are converted to loc;
+// times without one, and bare dates, are taken to be local to loc. dateOnly
+// reports a bare date, which is returned at midnight.
+func ParseTime(value string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), false, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.ParseInLocation(dateLayout, value, loc); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a date and time", value)
+}
+
+// Format writes t with the offset of its zone, the way Turvo takes dates.
+func Format(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// Times are a stop's appointment and planned window, in the stop's zone.
+type Times struct {
+	Zone *time.Location
+	Appt time.Time
+	// From and To bound the planned window: from the ready time to the
+	// appointment at a pickup, and from the appointment to the must-deliver
+	// time at a delivery. Both are zero when the stop sets no window.
+	From, To time.Time
+}
+
+// HasWindow reports whether the stop sets a planned window, which makes it
+// first come, first served rather than by appointment.
+func (t Times) HasWindow() bool {
+	return !t.From.IsZero()
+}
+
+// FieldError is a problem with one time field of a stop, such as its
+// apptTime.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ForStop reads the times of stop in its zone. A ready date without a time
+// opens the window at the start of that day, and a must-deliver date closes
+// it at the end of the day. The appointment, or some part of the window, must
+// fall within the stop's business hours; hours that cannot be parsed are not
+// checked. The error is the first problem Check finds.
+func ForStop(stop model.CLStop) (Times, error) {
+	times, problems := Check(stop)
+	if len(problems) > 0 {
+		return Times{}, problems[0]
+	}
+	return times, nil
+}
+
+// Check reads the times of stop like ForStop but reports every problem found.
+// The returned appointment is zero when it cannot be read; a bad ready or
+// must-deliver time leaves the stop without a window.
+func Check(stop model.CLStop) (Times, []FieldError) {
+	var problems []FieldError
+	add := func(field, message string) {
+		problems = append(problems, FieldError{Field: field, Message: message})
+	}
+
+	loc, err := Zone(stop)
+	if err != nil {
+		add("timezone", "is not a known IANA timezone")
+		return Times{}, problems
+	}
+	times := Times{Zone: loc}
+	// free-text hours such as "24/7 call ahead" are not checked
+	hours, err := ParseHours(stop.BusinessHours)
+	if err != nil {
+		hours = Hours{}
+	}
+
+	if strings.TrimSpace(stop.ApptTime) == "" {
+		add("apptTime", "is required")
+		return times, problems
+	}
+	if times.Appt, _, err = ParseTime(stop.ApptTime, loc); err != nil {
+		add("apptTime", "is not a date and time")
+		return times, problems
+	}
+
+	switch {
+	case stop.Type == model.StopTypePickup && strings.TrimSpace(stop.ReadyTime) != "":
+		ready, _, err := ParseTime(stop.ReadyTime, loc)
+		switch {
+		case err != nil:
+			add("readyTime", "is not a date and time")
+		case ready.After(times.Appt):
+			add("readyTime", "is after apptTime")
+		default:
+			times.From, times.To = ready, times.Appt
+		}
+	case stop.Type == model.StopTypeDelivery && strings.TrimSpace(stop.MustDeliver) != "":
+		due, dateOnly, err := ParseTime(stop.MustDeliver, loc)
+		if err != nil {
+			add("mustDeliver", "is not a date or date and time")
+			break
+		}
+		if dateOnly {
+			due = due.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		if due.Before(times.Appt) {
+			add("mustDeliver", "is before apptTime")
+		} else {
+			times.From, times.To = times.Appt, due
+		}
+	}
+
+	if times.HasWindow() {
+		if !hours.Overlaps(times.From, times.To) {
+			add("apptTime", fmt.Sprintf("window %s to %s is outside businessHours %q", Format(times.From), Format(times.To), stop.BusinessHours))
+		}
+	} else if !hours.Open(times.Appt) {
+		add("apptTime", fmt.Sprintf("%s is outside businessHours %q", Format(times.Appt), stop.BusinessHours))
+	}
+	return times, problems
+}
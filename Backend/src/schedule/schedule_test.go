@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZone(t *testing.T) {
+	for name, tc := range map[string]struct {
+		stop model.CLStop
+		want string
+	}{
+		"named":         {model.CLStop{Timezone: "America/Denver", State: "NY"}, "America/Denver"},
+		"state code":    {model.CLStop{State: "ga"}, "America/New_York"},
+		"state name":    {model.CLStop{State: "Arizona"}, "America/Phoenix"},
+		"zip override":  {model.CLStop{State: "TX", Zipcode: "79901"}, "America/Denver"},
+		"zip elsewhere": {model.CLStop{State: "TX", Zipcode: "75201"}, "America/Chicago"},
+		"province":      {model.CLStop{State: "ON"}, "America/Toronto"},
+		"unknown":       {model.CLStop{State: "string"}, DefaultZone},
+	} {
+		t.Run(name, func(t *testing.T) {
+			loc, err := Zone(tc.stop)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, loc.String())
+		})
+	}
+
+	_, err := Zone(model.CLStop{Timezone: "Mars/Olympus"})
+	assert.ErrorContains(t, err, `unknown timezone "Mars/Olympus"`)
+}
+
+func TestParseTime(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	require.NoError(t, err)
+
+	for value, want := range map[string]string{
+		"2025-08-01T13:00:00Z":      "2025-08-01T07:00:00-06:00",
+		"2025-08-01T09:00:00-04:00": "2025-08-01T07:00:00-06:00",
+		"2025-08-01T07:00:00":       "2025-08-01T07:00:00-06:00",
+		"2025-12-01 07:30":          "2025-12-01T07:30:00-07:00",
+		"2025-08-01":                "2025-08-01T00:00:00-06:00",
+	} {
+		got, _, err := ParseTime(value, denver)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, Format(got), value)
+	}
+
+	_, dateOnly, err := ParseTime("2025-08-01", denver)
+	require.NoError(t, err)
+	assert.True(t, dateOnly)
+
+	_, _, err = ParseTime("tomorrow", denver)
+	assert.Error(t, err)
+}
+
+func TestForStop(t *testing.T) {
+	pickup := model.CLStop{Type: model.StopTypePickup, State: "CA", ReadyTime: "2025-08-01T06:00", ApptTime: "2025-08-01T10:00"}
+	times, err := ForStop(pickup)
+	require.NoError(t, err)
+	assert.Equal(t, "America/Los_Angeles", times.Zone.String())
+	assert.Equal(t, "2025-08-01T10:00:00-07:00", Format(times.Appt))
+	require.True(t, times.HasWindow())
+	assert.Equal(t, "2025-08-01T06:00:00-07:00", Format(times.From))
+	assert.Equal(t, times.Appt, times.To)
+
+	delivery := model.CLStop{Type: model.StopTypeDelivery, State: "NY", ApptTime: "2025-08-03T09:00", MustDeliver: "2025-08-03"}
+	times, err = ForStop(delivery)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-08-03T09:00:00-04:00", Format(times.From))
+	assert.Equal(t, "2025-08-03T23:59:59-04:00", Format(times.To))
+
+	times, err = ForStop(model.CLStop{Type: model.StopTypeDelivery, State: "NY", ApptTime: "2025-08-03T09:00"})
+	require.NoError(t, err)
+	assert.False(t, times.HasWindow())
+}
+
+func TestForStop_Rejects(t *testing.T) {
+	for name, tc := range map[string]struct {
+		stop model.CLStop
+		want string
+	}{
+		"no appointment":   {model.CLStop{Type: model.StopTypePickup}, "apptTime is required"},
+		"bad appointment":  {model.CLStop{Type: model.StopTypePickup, ApptTime: "soon"}, "apptTime"},
+		"bad timezone":     {model.CLStop{Type: model.StopTypePickup, ApptTime: "2025-08-01T10:00", Timezone: "string"}, "timezone is not a known IANA timezone"},
+		"ready after appt": {model.CLStop{Type: model.StopTypePickup, ApptTime: "2025-08-01T10:00", ReadyTime: "2025-08-01T11:00"}, "readyTime is after apptTime"},
+		"due before appt":  {model.CLStop{Type: model.StopTypeDelivery, ApptTime: "2025-08-03T10:00", MustDeliver: "2025-08-02"}, "mustDeliver is before apptTime"},
+		"bad must deliver": {model.CLStop{Type: model.StopTypeDelivery, ApptTime: "2025-08-03T10:00", MustDeliver: "asap"}, "mustDeliver"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ForStop(tc.stop)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	times, problems := Check(model.CLStop{Type: model.StopTypeDelivery, BusinessHours: "weekdays", ApptTime: "2025-08-03T10:00", MustDeliver: "2025-08-02"})
+	assert.Equal(t, []FieldError{{"mustDeliver", "is before apptTime"}}, problems)
+	assert.Equal(t, "2025-08-03T10:00:00-05:00", Format(times.Appt))
+	assert.False(t, times.HasWindow())
+
+	times, problems = Check(model.CLStop{Type: model.StopTypePickup, ApptTime: "soon"})
+	assert.Equal(t, []FieldError{{"apptTime", "is not a date and time"}}, problems)
+	assert.True(t, times.Appt.IsZero())
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import "strings"
+
+// stateZones is the zone most of each US state and Canadian province keeps.
+var stateZones = map[string]string{
+	"AL": "America/Chicago", "AK": "America/Anchorage", "AZ": "America/Phoenix", "AR": "America/Chicago",
+	"CA": "America/Los_Angeles", "CO": "America/Denver", "CT": "America/New_York", "DE": "America/New_York",
+	"DC": "America/New_York", "FL": "America/New_York", "GA": "America/New_York", "HI": "Pacific/Honolulu",
+	"ID": "America/Boise", "IL": "America/Chicago", "IN": "America/Indiana/Indianapolis", "IA": "America/Chicago",
+	"KS": "America/Chicago", "KY": "America/New_York", "LA": "America/Chicago", "ME": "America/New_York",
+	"MD": "America/New_York", "MA": "America/New_York", "MI": "America/Detroit", "MN": "America/Chicago",
+	"MS": "America/Chicago", "MO": "America/Chicago", "MT": "America/Denver", "NE": "America/Chicago",
+	"NV": "America/Los_Angeles", "NH": "America/New_York", "NJ": "America/New_York", "NM": "America/Denver",
+	"NY": "America/New_York", "NC": "America/New_York", "ND": "America/Chicago", "OH": "America/New_York",
+	"OK": "America/Chicago", "OR": "America/Los_Angeles", "PA": "America/New_York", "RI": "America/New_York",
+	"SC": "America/New_York", "SD": "America/Chicago", "TN": "America/Chicago", "TX": "America/Chicago",
+	"UT": "America/Denver", "VT": "America/New_York", "VA": "America/New_York", "WA": "America/Los_Angeles",
+	"WV": "America/New_York", "WI": "America/Chicago", "WY": "America/Denver", "PR": "America/Puerto_Rico",
+
+	"AB": "America/Edmonton", "BC": "America/Vancouver", "MB": "America/Winnipeg", "NB": "America/Moncton",
+	"NL": "America/St_Johns", "NS": "America/Halifax", "ON": "America/Toronto", "PE": "America/Halifax",
+	"QC": "America/Toronto", "SK": "America/Regina",
+}
+
+// zipZones overrides the state zone for the three-digit zip prefixes of
+// areas that keep another zone than the rest of their state.
+var zipZones = map[string]string{
+	// Florida panhandle
+	"324": "America/Chicago",
+	"325": "America/Chicago",
+	// Chattanooga and East Tennessee
+	"373": "America/New_York",
+	"374": "America/New_York",
+	"376": "America/New_York",
+	"377": "America/New_York",
+	"378": "America/New_York",
+	"379": "America/New_York",
+	// West Kentucky
+	"420": "America/Chicago",
+	"421": "America/Chicago",
+	"422": "America/Chicago",
+	"423": "America/Chicago",
+	"424": "America/Chicago",
+	// Northwest Indiana and Evansville
+	"463": "America/Chicago",
+	"464": "America/Chicago",
+	"476": "America/Chicago",
+	"477": "America/Chicago",
+	// West South Dakota, southwest North Dakota and the Nebraska panhandle
+	"577": "America/Denver",
+	"586": "America/Denver",
+	"693": "America/Denver",
+	// El Paso
+	"798": "America/Denver",
+	"799": "America/Denver",
+	"885": "America/Denver",
+	// North Idaho
+	"835": "America/Los_Angeles",
+	"838": "America/Los_Angeles",
+	// Eastern Oregon
+	"979": "America/Boise",
+}
+
+// zoneFor returns the zone name for a state code and zip code.
+func zoneFor(state, zip string) (string, bool) {
+	zone, ok := stateZones[state]
+	if !ok {
+		return "", false
+	}
+	if zip = strings.TrimSpace(zip); len(zip) >= 3 {
+		if override, ok := zipZones[zip[:3]]; ok {
+			return override, true
+		}
+	}
+	return zone, true
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"drumkit.com/interview/src/model"
+)
+
+// defaultBatchConcurrency is how many loads CreateLoads creates at once when
+// LoadService.BatchConcurrency is not set.
+const defaultBatchConcurrency = 4
+
+// LoadResult is the outcome of creating one load of a batch.
+type LoadResult struct {
+	// Load is the created load; it is empty when Err is set.
+	Load model.CreatedLoad
+	Err  error
+}
+
+// CreateLoads creates every load and returns one result per load, in the
+// order given. A failed load does not stop the others; loads not yet started
+// when ctx ends fail with its error.
+func (s *LoadService) CreateLoads(ctx context.Context, loads []model.CreateLoadRequest) []LoadResult {
+	limit := s.BatchConcurrency
+	if limit <= 0 {
+		limit = defaultBatchConcurrency
+	}
+
+	results := make([]LoadResult, len(loads))
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range loads {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-slots; wg.Done() }()
+			results[i].Load, results[i].Err = s.CreateLoad(ctx, loads[i])
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// locationCreates makes sure concurrent loads create a new location once: the
+// first load to need it creates it while the others with the same key wait
+// for its ID. Lookups are not guarded, only the create step.
+type locationCreates struct {
+	mu    sync.Mutex
+	calls map[string]*locationCreate
+}
+
+type locationCreate struct {
+	done chan struct{}
+	id   int
+	err  error
+}
+
+// do runs create for key unless a create for key is already running, in which
+// case it waits for that one's ID. When the running create fails, waiting
+// callers try again themselves rather than share the other load's error.
+func (c *locationCreates) do(ctx context.Context, key string, create func() (int, error)) (int, error) {
+	for {
+		c.mu.Lock()
+		if c.calls == nil {
+			c.calls = map[string]*locationCreate{}
+		}
+		call, running := c.calls[key]
+		if !running {
+			call = &locationCreate{done: make(chan struct{})}
+			c.calls[key] = call
+		}
+		c.mu.Unlock()
+
+		if !running {
+			call.id, call.err = create()
+			c.mu.Lock()
+			delete(c.calls, key)
+			c.mu.Unlock()
+			close(call.done)
+			return call.id, call.err
+		}
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+		if call.err == nil {
+			return call.id, nil
+		}
+	}
+}
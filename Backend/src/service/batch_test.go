@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/model"
+)
+
+func (suite *LoadServiceTestSuite) TestCreateLoads_KeepsPartialProgress() {
+	suite.service.BatchConcurrency = 2
+	loads := []model.CreateLoadRequest{
+		suite.customerLoad(model.CLCustomer{Name: "test"}),
+		suite.carrierLoad(model.CLCarrier{}),
+		suite.customerLoad(model.CLCustomer{Name: "test"}),
+	}
+
+	results := suite.service.CreateLoads(context.Background(), loads)
+	suite.Require().Len(results, 3)
+	suite.NoError(results[0].Err)
+	suite.ErrorIs(results[1].Err, gateway.ErrValidation)
+	suite.Zero(results[1].Load.ID)
+	suite.NoError(results[2].Err)
+	suite.ElementsMatch([]int{results[0].Load.ID, results[2].Load.ID}, suite.turvo.ShipmentIDs())
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoads_SharesNewLocations() {
+	loads := make([]model.CreateLoadRequest, 4)
+	for i := range loads {
+		loads[i] = suite.customerLoad(model.CLCustomer{Name: "test"})
+		loads[i].Consignee = model.CLConsignee{Name: "New Dock", AddressLine1: "5 Harbor Rd", City: "Houston", State: "TX", Zipcode: "77001", ApptTime: "2025-08-01T15:00:00Z"}
+	}
+
+	for _, result := range suite.service.CreateLoads(context.Background(), loads) {
+		suite.NoError(result.Err)
+	}
+	suite.Len(suite.turvo.RequestsTo("POST", "/locations"), 1)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoads_Canceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := suite.service.CreateLoads(ctx, []model.CreateLoadRequest{
+		suite.customerLoad(model.CLCustomer{Name: "test"}),
+		suite.customerLoad(model.CLCustomer{Name: "test"}),
+	})
+	for _, result := range results {
+		suite.ErrorIs(result.Err, context.Canceled)
+	}
+	suite.Empty(suite.turvo.ShipmentIDs())
+}
+
+func (suite *LoadServiceTestSuite) TestLocationCreates_OnlyBlocksTheSameKey() {
+	var creates locationCreates
+	started, release := make(chan struct{}), make(chan struct{})
+	go creates.do(context.Background(), "dock", func() (int, error) {
+		close(started)
+		<-release
+		return 7, nil
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := creates.do(ctx, "dock", func() (int, error) { return 0, errors.New("created twice") })
+	suite.ErrorIs(err, context.Canceled, "a canceled load stops waiting")
+
+	id, err := creates.do(context.Background(), "other dock", func() (int, error) { return 8, nil })
+	suite.NoError(err)
+	suite.Equal(8, id, "other keys are not blocked")
+
+	close(release)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"drumkit.com/interview/src/model"
+)
+
+// ErrInvalidCursor is returned when a client sends a cursor we did not issue.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the state behind the opaque nextCursor token handed to clients.
+type cursor struct {
+	Start         int    `json:"s"`
+	PageSize      int    `json:"n,omitempty"`
+	LastObjectKey string `json:"k,omitempty"`
+}
+
+// EncodeCursor turns the query for the next page into an opaque token.
+func EncodeCursor(query model.LoadQuery) string {
+	raw, _ := json.Marshal(cursor{Start: query.Start, PageSize: query.PageSize, LastObjectKey: query.LastObjectKey})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor restores the query encoded by EncodeCursor.
+func DecodeCursor(token string) (model.LoadQuery, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return model.LoadQuery{}, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Start < 0 || c.PageSize < 0 {
+		return model.LoadQuery{}, ErrInvalidCursor
+	}
+	return model.LoadQuery{Start: c.Start, PageSize: c.PageSize, LastObjectKey: c.LastObjectKey}, nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	query := model.LoadQuery{Start: 48, PageSize: 24, LastObjectKey: "1000257597"}
+	decoded, err := DecodeCursor(EncodeCursor(query))
+	require.NoError(t, err)
+	assert.Equal(t, query, decoded)
+}
+
+func TestDecodeCursor_RejectsGarbage(t *testing.T) {
+	for _, token := range []string{"%%%", "bm90IGpzb24", "eyJzIjotNX0"} {
+		_, err := DecodeCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
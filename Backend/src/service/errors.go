@@ -0,0 +1,9 @@
+package service
+
+import "drumkit.com/interview/src/gateway"
+
+// validationError reports a load the service rejected before sending it to
+// the TMS, classified like a TMS validation failure.
+func validationError(op string, err error) error {
+	return &gateway.TurvoError{Kind: gateway.KindValidation, Op: op, Err: err}
+}
@@ -1,34 +1,557 @@
+// Package service creates, updates and moves loads in the TMS. Before a load
+// is created its carrier is matched by Turvo ID, then DOT, MC or SCAC number;
+// its customer by external ID, then exact name, then address; and each stop
+// by location name and an address score, creating the locations that are
+// missing. Ambiguous matches are returned with their candidates.
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"drumkit.com/interview/src/equipment"
 	"drumkit.com/interview/src/gateway"
 	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/carrier"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
+	"drumkit.com/interview/src/rating"
+	"drumkit.com/interview/src/schedule"
+	logger "drumkit.com/interview/src/utils"
+	"drumkit.com/interview/src/validation"
+	"go.uber.org/zap"
 )
 
 type LoadService struct {
-	gw *gateway.TurvoAPIGateway
+	gw gateway.TMS
+	// Equipment maps load specifications to Turvo equipment. Nil uses
+	// equipment.Default.
+	Equipment *equipment.Mapper
+	// Margin is the margin floor loads are checked against. The zero value
+	// has no floor.
+	Margin rating.MarginPolicy
+	// BatchConcurrency bounds how many loads CreateLoads creates at once.
+	// Zero uses defaultBatchConcurrency.
+	BatchConcurrency int
+
+	// newLocations lets loads created together share a location one of them
+	// creates rather than each creating it.
+	newLocations locationCreates
 }
 
-func NewLoadService(gw *gateway.TurvoAPIGateway) *LoadService {
+func NewLoadService(gw gateway.TMS) *LoadService {
 	return &LoadService{gw: gw}
 }
 
-func (s *LoadService) CreateLoad(load model.CreateLoadRequest) error {
-	pickUp, err := s.gw.RetrieveLocations(load.Pickup.Name)
+// CreateLoad resolves the load's stops, customer and carrier in the TMS and
+// creates it there, returning the new shipment and what it resolved to.
+func (s *LoadService) CreateLoad(ctx context.Context, load model.CreateLoadRequest) (model.CreatedLoad, error) {
+	stops, err := load.RouteStops()
+	if err != nil {
+		return model.CreatedLoad{}, validationError("create shipment", err)
+	}
+
+	flags, err := s.Margin.Check(load)
+	if err != nil {
+		return model.CreatedLoad{}, validationError("rate load", err)
+	}
+	for _, flag := range flags {
+		if logger.Logger == nil {
+			break
+		}
+		logger.Logger.Warn("load margin needs review", zap.String("reason", flag))
+	}
+
+	locationIDs, reviews, err := s.resolveLocations(ctx, stops)
+	if err != nil {
+		return model.CreatedLoad{}, err
+	}
+	for _, review := range reviews {
+		if logger.Logger == nil {
+			break
+		}
+		logger.Logger.Warn("low-confidence match needs review",
+			zap.String("entity", review.Entity), zap.String("query", review.Query),
+			zap.Int("matchId", review.Match.ID), zap.Float64("score", review.Score),
+			zap.String("reason", review.Reason))
+	}
+	loadEquipment := s.equipmentRules().Map(load)
+	refs := model.LoadRefs{LocationIDs: locationIDs, Equipment: &loadEquipment}
+
+	matchedCustomer, err := s.resolveCustomer(ctx, load.Customer)
+	if err != nil {
+		return model.CreatedLoad{}, err
+	}
+	refs.CustomerID, refs.CustomerName = matchedCustomer.ID, matchedCustomer.Name
+
+	matched, ok, err := s.resolveCarrier(ctx, load.Carrier)
+	if err != nil {
+		return model.CreatedLoad{}, err
+	}
+	if ok {
+		refs.CarrierID, refs.CarrierName = matched.ID, matched.Name
+	} else if load.RateData.CarrierLhRateUsd != 0 {
+		return model.CreatedLoad{}, validationError("resolve carrier", errors.New("carrier rate given without a carrier"))
+	}
+	shipment, err := s.gw.CreateLoad(ctx, load, refs)
+	if err != nil {
+		return model.CreatedLoad{}, err
+	}
+	return model.CreatedLoad{
+		ID:           shipment.ID,
+		CustomID:     shipment.CustomID,
+		LocationIDs:  refs.LocationIDs,
+		CustomerID:   refs.CustomerID,
+		CarrierID:    refs.CarrierID,
+		MatchReviews: reviews,
+		MarginFlags:  flags,
+	}, nil
+}
+
+func (s *LoadService) equipmentRules() *equipment.Mapper {
+	if s.Equipment == nil {
+		return equipment.Default()
+	}
+	return s.Equipment
+}
+
+// resolveCustomer finds the Turvo customer for c, trying in turn its
+// externalTMSId as a customer external ID, its exact name and its address.
+// Several name matches are narrowed by address; when more than one customer is
+// still left the lookup fails with the candidates.
+func (s *LoadService) resolveCustomer(ctx context.Context, c model.CLCustomer) (customer.Customer, error) {
+	var tried []string
+	if ext := strings.TrimSpace(c.ExternalTMSId); ext != "" {
+		tried = append(tried, fmt.Sprintf("external ID %q", ext))
+		found, err := s.gw.RetrieveCustomers(ctx, customer.Query{ExternalID: ext})
+		if err != nil {
+			return customer.Customer{}, err
+		}
+		if match, ok, err := pickCustomer(found, fmt.Sprintf("external ID %q", ext)); ok || err != nil {
+			return match, err
+		}
+	}
+
+	if name := strings.TrimSpace(c.Name); name != "" {
+		tried = append(tried, fmt.Sprintf("name %q", name))
+		found, err := s.gw.RetrieveCustomers(ctx, customer.Query{Name: name})
+		if err != nil {
+			return customer.Customer{}, err
+		}
+		var named []customer.Customer
+		for _, candidate := range found {
+			if strings.EqualFold(strings.TrimSpace(candidate.Name), name) {
+				named = append(named, candidate)
+			}
+		}
+		if len(named) > 1 {
+			if atAddress := customersAt(named, c); len(atAddress) > 0 {
+				named = atAddress
+			}
+		}
+		if match, ok, err := pickCustomer(named, fmt.Sprintf("name %q", name)); ok || err != nil {
+			return match, err
+		}
+	}
+
+	if hasCustomerAddress(c) {
+		address := strings.Join(nonEmpty(c.AddressLine1, c.City, c.State, c.Zipcode), ", ")
+		tried = append(tried, fmt.Sprintf("address %q", address))
+		found, err := s.gw.RetrieveCustomers(ctx, customer.Query{City: c.City, State: c.State, Zip: c.Zipcode})
+		if err != nil {
+			return customer.Customer{}, err
+		}
+		if match, ok, err := pickCustomer(customersAt(found, c), fmt.Sprintf("address %q", address)); ok || err != nil {
+			return match, err
+		}
+	}
+
+	if len(tried) == 0 {
+		return customer.Customer{}, validationError("resolve customer", errors.New("load has no customer"))
+	}
+	return customer.Customer{}, validationError("resolve customer", fmt.Errorf("no customer matches %s", strings.Join(tried, ", ")))
+}
+
+// pickCustomer returns the only customer in found. ok is false when found is
+// empty; several customers are an *model.AmbiguousMatchError.
+func pickCustomer(found []customer.Customer, by string) (customer.Customer, bool, error) {
+	switch len(found) {
+	case 0:
+		return customer.Customer{}, false, nil
+	case 1:
+		return found[0], true, nil
+	}
+	candidates := make([]model.Candidate, len(found))
+	for i, c := range found {
+		candidates[i] = model.Candidate{ID: c.ID, Name: c.Name}
+	}
+	return customer.Customer{}, false, &model.AmbiguousMatchError{Entity: "customer", By: by, Candidates: candidates}
+}
+
+// customersAt keeps the customers with a live address matching every address
+// field given on c.
+func customersAt(found []customer.Customer, c model.CLCustomer) []customer.Customer {
+	if len(nonEmpty(c.AddressLine1, c.City, c.State, c.Zipcode)) == 0 {
+		return nil
+	}
+	same := func(want, got string) bool {
+		want = strings.TrimSpace(want)
+		return want == "" || strings.EqualFold(want, strings.TrimSpace(got))
+	}
+	var out []customer.Customer
+	for _, candidate := range found {
+		for _, addr := range candidate.Address {
+			if !addr.Deleted && same(c.AddressLine1, addr.Line1) && same(c.City, addr.City) &&
+				same(c.State, addr.State) && same(c.Zipcode, addr.Zip) {
+				out = append(out, candidate)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// hasCustomerAddress reports whether c has enough of an address to narrow a
+// Turvo lookup: a city and state, or a zip code. A street line alone would
+// list every customer.
+func hasCustomerAddress(c model.CLCustomer) bool {
+	return strings.TrimSpace(c.Zipcode) != "" ||
+		(strings.TrimSpace(c.City) != "" && strings.TrimSpace(c.State) != "")
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// resolveCarrier finds the Turvo carrier for c. A numeric externalTMSId is
+// looked up as a Turvo ID first; when no carrier has it, the DOT, MC and SCAC
+// numbers are tried in that order and the first one matching exactly one
+// carrier wins. ok is false when the request names no carrier at all.
+func (s *LoadService) resolveCarrier(ctx context.Context, c model.CLCarrier) (carrier.Carrier, bool, error) {
+	var tried []string
+	if id, err := strconv.Atoi(strings.TrimSpace(c.ExternalTMSId)); err == nil && id > 0 {
+		tried = append(tried, fmt.Sprintf("Turvo ID %d", id))
+		found, err := s.gw.RetrieveCarriers(ctx, carrier.Query{ID: id})
+		if err != nil {
+			return carrier.Carrier{}, false, err
+		}
+		if len(found) == 1 {
+			return found[0], true, nil
+		}
+	}
+
+	queries := []struct {
+		label string
+		query carrier.Query
+	}{
+		{"DOT " + c.DotNumber, carrier.Query{DotNumber: c.DotNumber}},
+		{"MC " + c.McNumber, carrier.Query{McNumber: c.McNumber}},
+		{"SCAC " + c.Scac, carrier.Query{Scac: c.Scac}},
+	}
+	for _, q := range queries {
+		if strings.TrimSpace(q.query.DotNumber+q.query.McNumber+q.query.Scac) == "" {
+			continue
+		}
+		tried = append(tried, q.label)
+		found, err := s.gw.RetrieveCarriers(ctx, q.query)
+		if err != nil {
+			return carrier.Carrier{}, false, err
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], true, nil
+		default:
+			candidates := make([]model.Candidate, len(found))
+			for i, c := range found {
+				candidates[i] = model.Candidate{ID: c.ID, Name: c.Name}
+			}
+			return carrier.Carrier{}, false, &model.AmbiguousMatchError{Entity: "carrier", By: q.label, Candidates: candidates}
+		}
+	}
+
+	if len(tried) == 0 {
+		if strings.TrimSpace(c.Name) != "" {
+			return carrier.Carrier{}, false, validationError("resolve carrier", fmt.Errorf("carrier %q has no MC, DOT or SCAC number", c.Name))
+		}
+		return carrier.Carrier{}, false, nil
+	}
+	return carrier.Carrier{}, false, validationError("resolve carrier", fmt.Errorf("no carrier matches %s", strings.Join(tried, ", ")))
+}
+
+// resolveLocations finds the Turvo location for every stop: the location of
+// the same name whose address scores best against the stop's. Stops with no
+// good enough match get a new location created from their address and contact
+// details. Matches accepted with low confidence are returned for review. Each
+// distinct stop location is resolved once.
+func (s *LoadService) resolveLocations(ctx context.Context, stops []model.CLStop) ([]int, []model.MatchReview, error) {
+	resolved := map[string]int{}
+	ids := make([]int, len(stops))
+	var reviews []model.MatchReview
+	for i, stop := range stops {
+		key := locationKey(stop)
+		id, ok := resolved[key]
+		if !ok {
+			var review *model.MatchReview
+			var err error
+			if id, review, err = s.resolveLocation(ctx, i, stop, key); err != nil {
+				return nil, nil, err
+			}
+			if review != nil {
+				reviews = append(reviews, *review)
+			}
+			resolved[key] = id
+		}
+		ids[i] = id
+	}
+	return ids, reviews, nil
+}
+
+// locationKey is the normalized name and address of a stop's location.
+func locationKey(stop model.CLStop) string {
+	return strings.Join([]string{
+		normalizeWords(stop.Name), strings.Join(streetTokens(stop.AddressLine1), " "),
+		normalizeWords(stop.City), normalizeState(stop.State), zip5(stop.Zipcode),
+	}, "|")
+}
+
+func (s *LoadService) resolveLocation(ctx context.Context, i int, stop model.CLStop, key string) (int, *model.MatchReview, error) {
+	id, review, ok, err := s.findLocation(ctx, i, stop)
+	if ok || err != nil {
+		return id, review, err
+	}
+
+	if strings.TrimSpace(stop.Name) == "" || strings.TrimSpace(stop.AddressLine1) == "" || strings.TrimSpace(stop.City) == "" || strings.TrimSpace(stop.State) == "" {
+		return 0, nil, validationError("resolve location", fmt.Errorf("stop %d: no location named %q at this address, and no street, city and state to create it from", i+1, stop.Name))
+	}
+	id, err = s.newLocations.do(ctx, key, func() (int, error) {
+		// Another load may have created the location since it was looked up.
+		id, _, ok, err := s.findLocation(ctx, i, stop)
+		if ok || err != nil {
+			return id, err
+		}
+		created, err := s.gw.CreateLocation(ctx, newLocation(stop))
+		return created.ID, err
+	})
+	return id, nil, err
+}
+
+// findLocation looks up the Turvo location for a stop by name and address.
+// ok is false when no location matches well enough.
+func (s *LoadService) findLocation(ctx context.Context, i int, stop model.CLStop) (int, *model.MatchReview, bool, error) {
+	name := strings.TrimSpace(stop.Name)
+	found, err := s.gw.RetrieveLocations(ctx, name)
 	if err != nil {
-		return err
+		return 0, nil, false, err
+	}
+
+	var named []locations.Location
+	for _, loc := range found {
+		if strings.EqualFold(strings.TrimSpace(loc.Name), name) {
+			named = append(named, loc)
+		}
 	}
-	PickUpId := pickUp[0].ID
 
-	Delivery, err := s.gw.RetrieveLocations(load.Consignee.Name)
+	best, tied, ok := matchLocation(stop, named)
+	if len(tied) > 0 {
+		candidates := make([]model.Candidate, len(tied))
+		for j, loc := range tied {
+			candidates[j] = model.Candidate{ID: loc.ID, Name: loc.Name}
+		}
+		return 0, nil, false, &model.AmbiguousMatchError{Entity: "location", By: fmt.Sprintf("stop %d: name %q", i+1, name), Candidates: candidates}
+	}
+	if !ok {
+		return 0, nil, false, nil
+	}
+	var review *model.MatchReview
+	switch {
+	case best.nameOnly:
+		review = &model.MatchReview{Reason: "matched by name only; the location has no address to compare"}
+	case best.score < locationMatchThreshold:
+		review = &model.MatchReview{Reason: "address only partly matches"}
+	}
+	if review != nil {
+		review.Entity = "location"
+		review.Query = fmt.Sprintf("stop %d: %s", i+1, strings.Join(nonEmpty(stop.Name, stop.AddressLine1, stop.City, stop.State, stop.Zipcode), ", "))
+		review.Match = model.Candidate{ID: best.location.ID, Name: best.location.Name}
+		review.Score = best.score
+	}
+	return best.location.ID, review, true, nil
+}
+
+// newLocation builds the Turvo location for a stop that has none.
+func newLocation(stop model.CLStop) locations.CreateRequest {
+	loc := locations.CreateRequest{
+		Name:     strings.TrimSpace(stop.Name),
+		Timezone: stop.Timezone,
+		Addresses: []locations.Address{{
+			Line1:   stop.AddressLine1,
+			Line2:   stop.AddressLine2,
+			City:    stop.City,
+			State:   stop.State,
+			Zip:     stop.Zipcode,
+			Country: stop.Country,
+		}},
+	}
+	if zone, err := schedule.Zone(stop); err == nil {
+		loc.Timezone = zone.String()
+	}
+	if stop.Phone != "" {
+		loc.Phones = []locations.Phone{{Number: stop.Phone}}
+	}
+	if stop.Email != "" {
+		loc.Emails = []locations.Email{{Email: stop.Email}}
+	}
+	if stop.Contact != "" {
+		loc.Contacts = []locations.Contact{{Name: stop.Contact, Phone: stop.Phone, Email: stop.Email}}
+	}
+	var notes []string
+	if stop.BusinessHours != "" {
+		notes = append(notes, locations.BusinessHoursNote+stop.BusinessHours)
+	}
+	if stop.RefNumber != "" {
+		notes = append(notes, "Ref #: "+stop.RefNumber)
+	}
+	loc.Notes = strings.Join(notes, "\n")
+	return loc
+}
+
+// GetLoad returns a single load mapped into the Drumkit load format.
+func (s *LoadService) GetLoad(ctx context.Context, id int) (model.LoadDetail, error) {
+	shipment, err := s.gw.GetLoad(ctx, id)
 	if err != nil {
-		return err
+		return model.LoadDetail{}, err
 	}
+	return gateway.LoadFromShipment(shipment), nil
+}
 
-	DeliveryId := Delivery[0].ID
-	return s.gw.CreateLoad([]model.CreateLoadRequest{load}, PickUpId, DeliveryId)
+// UpdateLoad merges patch over the load's current state and sends Turvo only
+// the sections that changed. It returns the load as stored after the update.
+func (s *LoadService) UpdateLoad(ctx context.Context, id int, patch model.LoadPatch) (model.LoadDetail, error) {
+	current, err := s.gw.GetLoad(ctx, id)
+	if err != nil {
+		return model.LoadDetail{}, err
+	}
+
+	base := gateway.LoadFromShipment(current).CreateLoadRequest
+	desired, err := patch.Apply(base)
+	if err != nil {
+		return model.LoadDetail{}, err
+	}
+	// check the whole route, whichever object the patch re-timed a stop through
+	desired.Stops = gateway.RouteFor(base, desired)
+	if err := s.addBusinessHours(ctx, base.Stops, desired.Stops); err != nil {
+		return model.LoadDetail{}, err
+	}
+	if err := validation.CreateLoad(desired); err != nil {
+		return model.LoadDetail{}, err
+	}
+
+	update, err := gateway.ShipmentUpdateFor(current, desired, s.equipmentRules())
+	if err != nil {
+		return model.LoadDetail{}, err
+	}
+	if update.Empty() {
+		return gateway.LoadFromShipment(current), nil
+	}
+
+	updated, err := s.gw.UpdateLoad(ctx, id, update)
+	if err != nil {
+		return model.LoadDetail{}, err
+	}
+	return gateway.LoadFromShipment(updated), nil
+}
+
+// addBusinessHours fills in the business hours of every re-timed stop in
+// route that the patch gave none from its Turvo location, so the new times
+// are checked against them. Turvo keeps a location's hours in its notes.
+func (s *LoadService) addBusinessHours(ctx context.Context, base, route []model.CLStop) error {
+	for i := range route {
+		stop := &route[i]
+		if i >= len(base) || stop.BusinessHours != "" || !retimed(base[i], *stop) {
+			continue
+		}
+		id, err := strconv.Atoi(stop.ExternalTMSId)
+		if err != nil {
+			continue
+		}
+		loc, err := s.gw.GetLocation(ctx, id)
+		if err != nil {
+			return err
+		}
+		stop.BusinessHours = loc.BusinessHours()
+	}
+	return nil
+}
+
+// retimed reports whether the times of a stop differ between from and to.
+func retimed(from, to model.CLStop) bool {
+	return from.ApptTime != to.ApptTime || from.ReadyTime != to.ReadyTime ||
+		from.MustDeliver != to.MustDeliver || from.Timezone != to.Timezone
+}
+
+// ChangeStatus moves a load to a new status, refusing transitions the status
+// graph does not allow. It returns the load as stored after the change.
+func (s *LoadService) ChangeStatus(ctx context.Context, id int, change model.StatusChange) (model.LoadDetail, error) {
+	current, err := s.gw.GetLoad(ctx, id)
+	if err != nil {
+		return model.LoadDetail{}, err
+	}
+
+	from := model.ShipmentStatusCode(current.Status.Code.Key)
+	if err := model.CheckTransition(from, change.Status); err != nil {
+		return model.LoadDetail{}, err
+	}
+
+	updated, err := s.gw.UpdateStatus(ctx, id, change)
+	if err != nil {
+		return model.LoadDetail{}, err
+	}
+	return gateway.LoadFromShipment(updated), nil
+}
+
+// RetrieveLoads returns one page of loads and a cursor for the next page.
+func (s *LoadService) RetrieveLoads(ctx context.Context, query model.LoadQuery) (model.LoadPage, error) {
+	details, err := s.gw.RetrieveLoads(ctx, query)
+	if err != nil {
+		return model.LoadPage{}, err
+	}
+
+	loads := details.Shipments
+	if loads == nil {
+		loads = []model.Shipment{}
+	}
+	page := model.LoadPage{
+		Loads: loads,
+		Pagination: model.PageInfo{
+			Start:         query.Start,
+			PageSize:      details.Pagination.PageSize,
+			Count:         len(loads),
+			MoreAvailable: details.Pagination.MoreAvailable,
+		},
+	}
+	if page.Pagination.PageSize == 0 {
+		page.Pagination.PageSize = query.PageSize
+	}
+	if details.Pagination.MoreAvailable {
+		page.Pagination.NextCursor = EncodeCursor(gateway.NextLoadQuery(query, details))
+	}
+	return page, nil
 }
 
-func (s *LoadService) RetrieveLoads(start, pageSize string) ([]model.Shipment, error) {
-	return s.gw.RetrieveLoads(start, pageSize)
+// RetrieveAllLoads walks every page of shipments, fetching at most maxPages
+// pages of pageSize each. The returned flag is true when the limit cut the
+// result short.
+func (s *LoadService) RetrieveAllLoads(ctx context.Context, pageSize, maxPages int) ([]model.Shipment, bool, error) {
+	return gateway.CollectShipments(ctx, s.gw, model.LoadQuery{PageSize: pageSize}, maxPages)
 }
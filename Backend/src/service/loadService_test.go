@@ -1,26 +1,33 @@
 package service
 
 import (
-	"os"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"testing"
 
+	"drumkit.com/interview/src/equipment"
 	"drumkit.com/interview/src/gateway"
 	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/carrier"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
+	"drumkit.com/interview/src/rating"
+	"drumkit.com/interview/src/test/faketurvo"
 	"github.com/stretchr/testify/suite"
 )
 
 type LoadServiceTestSuite struct {
 	suite.Suite
+	turvo   *faketurvo.Server
 	service *LoadService
 }
 
-func (suite *LoadServiceTestSuite) SetupSuite() {
-	os.Setenv("TURVO_BASE_URL", "https://my-sandbox-publicapi.turvo.com/v1")
-	os.Setenv("TURVO_API_KEY", "9VjKgnIlQS1255cn7cRvJ6jNf8Z4MElP1PGgBTsH")
-	os.Setenv("TURVO_CLIENT_ID", "publicapi")
-	os.Setenv("TURVO_CLIENT_SECRET", "secret")
-	os.Setenv("TURVO_USERNAME", "axle@wickerparklogistics.com")
-	os.Setenv("TURVO_PASSWORD", "DZJ@pcu_qzd8ecz0fgw")
+func (suite *LoadServiceTestSuite) SetupTest() {
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.turvo.AddLocation(locations.Location{Name: "test"})
+	suite.turvo.AddCustomer(customer.Customer{Name: "test"})
 
 	gw := gateway.NewTurvoAPIGateway()
 	suite.Require().NotNil(gw)
@@ -29,6 +36,7 @@ func (suite *LoadServiceTestSuite) SetupSuite() {
 
 func (suite *LoadServiceTestSuite) TestCreateLoad_Success() {
 	loadReq := model.CreateLoadRequest{
+		Customer: model.CLCustomer{Name: "test"},
 		Pickup: model.CLPickup{
 			Name:     "test",
 			ApptTime: "2023-10-01T08:00:00Z",
@@ -50,8 +58,386 @@ func (suite *LoadServiceTestSuite) TestCreateLoad_Success() {
 		},
 		TotalWeight: 10000,
 	}
-	err := suite.service.CreateLoad(loadReq)
-	suite.NoError(err)
+	created, err := suite.service.CreateLoad(context.Background(), loadReq)
+	suite.Require().NoError(err)
+	suite.Equal([]int{created.ID}, suite.turvo.ShipmentIDs())
+	suite.Equal("FAKE-"+strconv.Itoa(created.ID), created.CustomID)
+	suite.Equal(suite.createdLocationIDs(), created.LocationIDs)
+	suite.NotZero(created.CustomerID)
+	suite.Zero(created.CarrierID)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_ResolvesEveryStop() {
+	a := suite.turvo.AddLocation(locations.Location{Name: "A"})
+	b := suite.turvo.AddLocation(locations.Location{Name: "B"})
+	loadReq := model.CreateLoadRequest{
+		Status:   "Covered",
+		Customer: model.CLCustomer{Name: "test"},
+		Stops: []model.CLStop{
+			{Type: "pickup", Name: "A", ApptTime: "2025-08-01T08:00:00Z"},
+			{Type: "delivery", Name: "B", ApptTime: "2025-08-01T12:00:00Z"},
+			{Type: "delivery", Name: "A", ApptTime: "2025-08-01T16:00:00Z"},
+		},
+	}
+	suite.create(loadReq)
+	suite.Len(suite.turvo.RequestsTo("GET", "/locations/list"), 2)
+
+	doc, _ := suite.turvo.Shipment(suite.turvo.ShipmentIDs()[0])
+	var shipment model.ShipmentDetail
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+	suite.Require().Len(shipment.GlobalRoute, 3)
+	suite.Equal([]int{a, b, a}, []int{shipment.GlobalRoute[0].Location.ID, shipment.GlobalRoute[1].Location.ID, shipment.GlobalRoute[2].Location.ID})
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_UnknownLocation() {
+	loadReq := model.CreateLoadRequest{
+		Status:    "Covered",
+		Pickup:    model.CLPickup{Name: "test", ApptTime: "2025-08-01T08:00:00Z"},
+		Consignee: model.CLConsignee{Name: "nowhere", ApptTime: "2025-08-01T12:00:00Z"},
+	}
+	_, err := suite.service.CreateLoad(context.Background(), loadReq)
+	suite.ErrorIs(err, gateway.ErrValidation)
+	suite.ErrorContains(err, `no location named "nowhere"`)
+	suite.Empty(suite.turvo.ShipmentIDs())
+}
+
+func (suite *LoadServiceTestSuite) createdLocationIDs() []int {
+	ids := suite.turvo.ShipmentIDs()
+	doc, _ := suite.turvo.Shipment(ids[len(ids)-1])
+	var shipment model.ShipmentDetail
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+	var out []int
+	for _, stop := range shipment.GlobalRoute {
+		out = append(out, stop.Location.ID)
+	}
+	return out
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_MatchesLocationByAddress() {
+	suite.turvo.AddLocation(locations.Location{Name: "Dock", Addresses: []locations.Address{{Line1: "1 Main St", Zip: "60601"}}})
+	elm := suite.turvo.AddLocation(locations.Location{Name: "Dock", Addresses: []locations.Address{{Line1: "9 Elm St", Zip: "75201"}}})
+
+	load := suite.customerLoad(model.CLCustomer{Name: "test"})
+	load.Consignee = model.CLConsignee{Name: "dock", AddressLine1: "9 elm st.", Zipcode: "75201-1234", ApptTime: "2025-08-01T12:00:00Z"}
+	suite.create(load)
+	suite.Equal(elm, suite.createdLocationIDs()[1])
+	suite.Empty(suite.turvo.RequestsTo("POST", "/locations"))
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_CreatesMissingLocation() {
+	load := suite.customerLoad(model.CLCustomer{Name: "test"})
+	load.Consignee = model.CLConsignee{
+		Name:          "New Dock",
+		AddressLine1:  "5 Harbor Rd",
+		City:          "Houston",
+		State:         "TX",
+		Zipcode:       "77001",
+		Contact:       "Sam",
+		Phone:         "555-0100",
+		BusinessHours: "Mon-Fri 08:00-17:00",
+		Timezone:      "America/Chicago",
+		ApptTime:      "2025-08-01T15:00:00Z",
+	}
+	created := suite.create(load)
+
+	reqs := suite.turvo.RequestsTo("POST", "/locations")
+	suite.Require().Len(reqs, 1)
+	var body locations.CreateRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &body))
+	suite.Equal("New Dock", body.Name)
+	suite.Equal([]locations.Address{{Line1: "5 Harbor Rd", City: "Houston", State: "TX", Zip: "77001"}}, body.Addresses)
+	suite.Equal([]locations.Contact{{Name: "Sam", Phone: "555-0100"}}, body.Contacts)
+	suite.Equal("Business hours: Mon-Fri 08:00-17:00", body.Notes)
+
+	found, err := gateway.NewTurvoAPIGateway().RetrieveLocations(context.Background(), "New Dock")
+	suite.Require().NoError(err)
+	suite.Require().Len(found, 1)
+	suite.Equal(found[0].ID, suite.createdLocationIDs()[1])
+	suite.Equal(found[0].ID, created.LocationIDs[1])
+
+	// a second load reuses the location it created
+	suite.create(load)
+	suite.Len(suite.turvo.RequestsTo("POST", "/locations"), 1)
+}
+
+func (suite *LoadServiceTestSuite) TestResolveLocations_ReportsLowConfidence() {
+	dc := suite.turvo.AddLocation(locations.Location{Name: "Walmart DC", Addresses: []locations.Address{{Line1: "1200 N Main St", City: "Bentonville", State: "AR", Zip: "72712"}}})
+	suite.turvo.AddLocation(locations.Location{Name: "Walmart DC", Addresses: []locations.Address{{Line1: "500 Commerce St", City: "Dallas", State: "TX", Zip: "75201"}}})
+
+	stops := []model.CLStop{
+		{Type: "pickup", Name: "Walmart DC", AddressLine1: "1200 North Main Street", City: "Bentonville", State: "AR"},
+		{Type: "delivery", Name: "Walmart DC", AddressLine1: "1200 Main St", City: "Bentonville", Zipcode: "72799"},
+	}
+	ids, reviews, err := suite.service.resolveLocations(context.Background(), stops)
+	suite.Require().NoError(err)
+	suite.Equal([]int{dc, dc}, ids)
+	suite.Require().Len(reviews, 1)
+	suite.Equal("location", reviews[0].Entity)
+	suite.Equal(dc, reviews[0].Match.ID)
+	suite.Contains(reviews[0].Query, "stop 2")
+	suite.Less(reviews[0].Score, locationMatchThreshold)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_AmbiguousLocation() {
+	suite.turvo.AddLocation(locations.Location{Name: "Dock"})
+	suite.turvo.AddLocation(locations.Location{Name: "Dock"})
+
+	load := suite.customerLoad(model.CLCustomer{Name: "test"})
+	load.Consignee = model.CLConsignee{Name: "Dock", ApptTime: "2025-08-01T12:00:00Z"}
+	_, err := suite.service.CreateLoad(context.Background(), load)
+	var ambiguous *model.AmbiguousMatchError
+	suite.Require().ErrorAs(err, &ambiguous)
+	suite.Equal("location", ambiguous.Entity)
+	suite.Len(ambiguous.Candidates, 2)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_UsesEquipmentRules() {
+	rules, err := equipment.Load(strings.NewReader(`{"rules": [
+		{"name": "step deck", "when": {"oversized": true}, "type": {"key": "9003", "value": "Step deck"}, "size": {"key": "9101", "value": "48ft"}},
+		{"name": "van", "type": {"key": "1200", "value": "Van"}, "size": {"key": "1000", "value": "53ft"}}
+	]}`))
+	suite.Require().NoError(err)
+	suite.service.Equipment = rules
+
+	load := suite.customerLoad(model.CLCustomer{Name: "test"})
+	load.TotalWeight = 42000
+	load.Specifications.Oversized = true
+	suite.create(load)
+
+	doc, _ := suite.turvo.Shipment(suite.turvo.ShipmentIDs()[0])
+	var shipment model.ShipmentDetail
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+	suite.Require().Len(shipment.Equipment, 1)
+	suite.Equal(model.ValueKey{Key: "9003", Value: "Step deck"}, shipment.Equipment[0].Type)
+	suite.Equal(model.ValueKey{Key: "9101", Value: "48ft"}, shipment.Equipment[0].Size)
+	suite.Equal(42000, shipment.Equipment[0].Weight)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_ChecksMargin() {
+	suite.turvo.AddCarrier(carrier.Carrier{Name: "Fast Freight", DotNumber: "123456"})
+	suite.service.Margin = rating.MarginPolicy{FloorPercent: 10, Enforce: true}
+
+	load := suite.carrierLoad(model.CLCarrier{DotNumber: "123456"})
+	load.RateData.CustomerLhRateUsd = 950
+	_, err := suite.service.CreateLoad(context.Background(), load)
+	suite.ErrorIs(err, gateway.ErrValidation)
+	suite.ErrorContains(err, "margin 5.3% is below the 10.0% floor")
+
+	load.RateData.CustomerLhRateUsd = 1000
+	load.RateData.CarrierMaxRate = 850
+	_, err = suite.service.CreateLoad(context.Background(), load)
+	suite.ErrorContains(err, "exceeds carrierMaxRate")
+	suite.Empty(suite.turvo.RequestsTo("POST", "/shipments"))
+
+	load.RateData.CarrierMaxRate = 900
+	load.RateData.NetProfitUsd = 100
+	suite.create(load)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_InvalidRoute() {
+	_, err := suite.service.CreateLoad(context.Background(), model.CreateLoadRequest{
+		Stops: []model.CLStop{{Type: "delivery", Name: "test"}, {Type: "pickup", Name: "test"}},
+	})
+	suite.ErrorIs(err, gateway.ErrValidation)
+	suite.Empty(suite.turvo.RequestsTo("GET", "/locations/list"))
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_ReturnsItemsToReview() {
+	suite.turvo.AddCarrier(carrier.Carrier{Name: "Fast Freight", DotNumber: "123456"})
+	suite.service.Margin = rating.MarginPolicy{FloorPercent: 10}
+
+	load := suite.carrierLoad(model.CLCarrier{DotNumber: "123456"})
+	load.RateData.CustomerLhRateUsd = 950
+	load.Pickup.City, load.Pickup.State = "Chicago", "IL"
+	created := suite.create(load)
+	suite.NotZero(created.CarrierID)
+	suite.Require().Len(created.MarginFlags, 1)
+	suite.Contains(created.MarginFlags[0], "below the 10.0% floor")
+	suite.Require().NotEmpty(created.MatchReviews)
+	suite.Equal("location", created.MatchReviews[0].Entity)
+}
+
+// create creates load and fails the test if that fails.
+func (suite *LoadServiceTestSuite) create(load model.CreateLoadRequest) model.CreatedLoad {
+	created, err := suite.service.CreateLoad(context.Background(), load)
+	suite.Require().NoError(err)
+	return created
+}
+
+func (suite *LoadServiceTestSuite) carrierLoad(c model.CLCarrier) model.CreateLoadRequest {
+	return model.CreateLoadRequest{
+		Status:    "Covered",
+		Customer:  model.CLCustomer{Name: "test"},
+		Pickup:    model.CLPickup{Name: "test", ApptTime: "2025-08-01T08:00:00Z"},
+		Consignee: model.CLConsignee{Name: "test", ApptTime: "2025-08-01T12:00:00Z"},
+		Carrier:   c,
+		RateData:  model.CLRateData{CarrierLhRateUsd: 900},
+	}
+}
+
+func (suite *LoadServiceTestSuite) createdCarrierOrders() []model.CarrierOrderDetail {
+	doc, _ := suite.turvo.Shipment(suite.turvo.ShipmentIDs()[0])
+	var shipment model.ShipmentDetail
+	raw, _ := json.Marshal(doc)
+	suite.Require().NoError(json.Unmarshal(raw, &shipment))
+	return shipment.CarrierOrder
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_ResolvesCarrier() {
+	suite.turvo.AddCarrier(carrier.Carrier{Name: "Other", McNumber: "MC-1"})
+	id := suite.turvo.AddCarrier(carrier.Carrier{Name: "Fast Freight", DotNumber: "123456", McNumber: "MC-1"})
+
+	// DOT is tried first, so the shared MC number never makes it ambiguous
+	load := suite.carrierLoad(model.CLCarrier{Name: "fast freight", DotNumber: "123456", McNumber: "MC-1"})
+	suite.create(load)
+	suite.Len(suite.turvo.RequestsTo("GET", "/carriers/list"), 1)
+
+	orders := suite.createdCarrierOrders()
+	suite.Require().Len(orders, 1)
+	suite.Equal(model.CarrierAvro{ID: id, Name: "Fast Freight"}, orders[0].Carrier)
+	suite.Equal(900.0, orders[0].Costs.TotalAmount)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_FallsBackToSCAC() {
+	id := suite.turvo.AddCarrier(carrier.Carrier{Name: "Fast Freight", Scac: "FFRT"})
+
+	load := suite.carrierLoad(model.CLCarrier{DotNumber: "999", McNumber: "MC-9", Scac: "ffrt"})
+	suite.create(load)
+	suite.Len(suite.turvo.RequestsTo("GET", "/carriers/list"), 3)
+	suite.Equal(id, suite.createdCarrierOrders()[0].Carrier.ID)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_CarrierExternalID() {
+	suite.turvo.AddCarrier(carrier.Carrier{ID: 834187, Name: "Fast Freight"})
+
+	load := suite.carrierLoad(model.CLCarrier{ExternalTMSId: "834187", DotNumber: "123456"})
+	suite.create(load)
+	suite.Len(suite.turvo.RequestsTo("GET", "/carriers/list"), 1)
+	suite.Equal(model.CarrierAvro{ID: 834187, Name: "Fast Freight"}, suite.createdCarrierOrders()[0].Carrier)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_UnknownCarrierIDFallsBackToDOT() {
+	id := suite.turvo.AddCarrier(carrier.Carrier{Name: "Fast Freight", DotNumber: "123456"})
+
+	load := suite.carrierLoad(model.CLCarrier{ExternalTMSId: "404", DotNumber: "123456"})
+	suite.create(load)
+	suite.Len(suite.turvo.RequestsTo("GET", "/carriers/list"), 2)
+	suite.Equal(id, suite.createdCarrierOrders()[0].Carrier.ID)
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_AmbiguousCarrier() {
+	a := suite.turvo.AddCarrier(carrier.Carrier{Name: "A", McNumber: "MC-2"})
+	b := suite.turvo.AddCarrier(carrier.Carrier{Name: "B", McNumber: "MC-2"})
+
+	_, err := suite.service.CreateLoad(context.Background(), suite.carrierLoad(model.CLCarrier{McNumber: "MC-2"}))
+	var ambiguous *model.AmbiguousMatchError
+	suite.Require().ErrorAs(err, &ambiguous)
+	suite.Equal("carrier", ambiguous.Entity)
+	suite.Equal([]model.Candidate{{ID: a, Name: "A"}, {ID: b, Name: "B"}}, ambiguous.Candidates)
+	suite.Empty(suite.turvo.ShipmentIDs())
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_CarrierNotMatched() {
+	for name, tc := range map[string]struct {
+		carrier model.CLCarrier
+		message string
+	}{
+		"unknown":       {model.CLCarrier{DotNumber: "404", Scac: "NONE"}, "no carrier matches DOT 404, SCAC NONE"},
+		"unknown ID":    {model.CLCarrier{ExternalTMSId: "404", Name: "Fast Freight"}, "no carrier matches Turvo ID 404"},
+		"no identifier": {model.CLCarrier{Name: "Fast Freight"}, `carrier "Fast Freight" has no MC, DOT or SCAC number`},
+		"rate only":     {model.CLCarrier{}, "carrier rate given without a carrier"},
+	} {
+		suite.Run(name, func() {
+			_, err := suite.service.CreateLoad(context.Background(), suite.carrierLoad(tc.carrier))
+			suite.ErrorIs(err, gateway.ErrValidation)
+			suite.ErrorContains(err, tc.message)
+			suite.Empty(suite.turvo.ShipmentIDs())
+		})
+	}
+}
+
+func (suite *LoadServiceTestSuite) customerLoad(c model.CLCustomer) model.CreateLoadRequest {
+	return model.CreateLoadRequest{
+		Status:    "Covered",
+		Pickup:    model.CLPickup{Name: "test", ApptTime: "2025-08-01T08:00:00Z"},
+		Consignee: model.CLConsignee{Name: "test", ApptTime: "2025-08-01T12:00:00Z"},
+		Customer:  c,
+	}
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_ResolvesCustomer() {
+	bunker := suite.turvo.AddCustomer(customer.Customer{
+		Name:        "Bunker",
+		ExternalIds: []customer.ExternalID{{Value: "ACME-7"}},
+		Address:     []customer.AddressEntry{{Line1: "1 Main St", City: "Chicago", State: "IL", Zip: "60601"}},
+	})
+	dallas := suite.turvo.AddCustomer(customer.Customer{
+		Name:    "Bunker",
+		Address: []customer.AddressEntry{{Line1: "9 Elm St", City: "Dallas", State: "TX"}},
+	})
+
+	for name, tc := range map[string]struct {
+		customer model.CLCustomer
+		want     int
+	}{
+		"turvo id is not an external id": {model.CLCustomer{ExternalTMSId: strconv.Itoa(dallas), Name: "test"}, suite.customerID("test")},
+		"external id":                    {model.CLCustomer{ExternalTMSId: "acme-7", Name: "Someone else"}, bunker},
+		"zip only":                       {model.CLCustomer{Name: "Bunker Inc", Zipcode: "60601"}, bunker},
+		"name and address":               {model.CLCustomer{Name: "bunker", City: "Dallas", State: "TX"}, dallas},
+		"unknown external id":            {model.CLCustomer{ExternalTMSId: "nope", Name: "test"}, suite.customerID("test")},
+		"address only":                   {model.CLCustomer{Name: "Bunker Inc", AddressLine1: "1 main st", City: "Chicago", State: "IL"}, bunker},
+	} {
+		suite.Run(name, func() {
+			suite.create(suite.customerLoad(tc.customer))
+			ids := suite.turvo.ShipmentIDs()
+			doc, _ := suite.turvo.Shipment(ids[len(ids)-1])
+			var shipment model.ShipmentDetail
+			raw, _ := json.Marshal(doc)
+			suite.Require().NoError(json.Unmarshal(raw, &shipment))
+			suite.Equal(tc.want, shipment.CustomerOrder[0].Customer.ID)
+		})
+	}
+}
+
+func (suite *LoadServiceTestSuite) customerID(name string) int {
+	found, err := gateway.NewTurvoAPIGateway().RetrieveCustomers(context.Background(), customer.Query{Name: name})
+	suite.Require().NoError(err)
+	suite.Require().Len(found, 1)
+	return found[0].ID
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_AmbiguousCustomer() {
+	a := suite.turvo.AddCustomer(customer.Customer{Name: "Bunker", Address: []customer.AddressEntry{{City: "Chicago", State: "IL"}}})
+	b := suite.turvo.AddCustomer(customer.Customer{Name: "Bunker", Address: []customer.AddressEntry{{City: "Chicago", State: "IL"}}})
+	suite.turvo.AddCustomer(customer.Customer{Name: "Bunker", Address: []customer.AddressEntry{{City: "Dallas", State: "TX"}}})
+
+	_, err := suite.service.CreateLoad(context.Background(), suite.customerLoad(model.CLCustomer{Name: "Bunker", City: "Chicago", State: "IL"}))
+	var ambiguous *model.AmbiguousMatchError
+	suite.Require().ErrorAs(err, &ambiguous)
+	suite.Equal("customer", ambiguous.Entity)
+	suite.Equal([]model.Candidate{{ID: a, Name: "Bunker"}, {ID: b, Name: "Bunker"}}, ambiguous.Candidates)
+	suite.ErrorContains(err, `name "Bunker" matches 2 customers`)
+	suite.Empty(suite.turvo.ShipmentIDs())
+}
+
+func (suite *LoadServiceTestSuite) TestCreateLoad_CustomerNotMatched() {
+	_, err := suite.service.CreateLoad(context.Background(), suite.customerLoad(model.CLCustomer{ExternalTMSId: "404", Name: "Nobody"}))
+	suite.ErrorIs(err, gateway.ErrValidation)
+	suite.ErrorContains(err, `no customer matches external ID "404", name "Nobody"`)
+
+	_, err = suite.service.CreateLoad(context.Background(), suite.customerLoad(model.CLCustomer{Name: "Nobody", AddressLine1: "1 Main St"}))
+	suite.ErrorContains(err, `no customer matches name "Nobody"`, "a street line alone is not looked up")
+	for _, r := range suite.turvo.RequestsTo("GET", "/customers/list") {
+		suite.NotEmpty(r.Query, "every customer lookup is filtered")
+	}
+
+	_, err = suite.service.CreateLoad(context.Background(), suite.customerLoad(model.CLCustomer{}))
+	suite.ErrorIs(err, gateway.ErrValidation)
+	suite.ErrorContains(err, "load has no customer")
+	suite.Empty(suite.turvo.ShipmentIDs())
 }
 
 func TestLoadServiceTestSuite(t *testing.T) {
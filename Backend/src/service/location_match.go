@@ -0,0 +1,221 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"drumkit.com/interview/src/model"
+	locations "drumkit.com/interview/src/model/location"
+)
+
+const (
+	// locationMatchThreshold is the score above which a location match is
+	// trusted outright.
+	locationMatchThreshold = 0.85
+	// locationReviewThreshold is the lowest score still accepted as a match;
+	// matches below locationMatchThreshold are reported for review.
+	locationReviewThreshold = 0.5
+)
+
+// Weights of each address field in a location score. Fields the stop does not
+// give are left out and the rest rescaled.
+const (
+	streetWeight = 0.4
+	cityWeight   = 0.15
+	stateWeight  = 0.15
+	zipWeight    = 0.3
+)
+
+// scoredLocation is a candidate location and how well it matches a stop.
+type scoredLocation struct {
+	location locations.Location
+	score    float64
+	// nameOnly is set when the stop has an address but no candidate has one
+	// to compare it with, so only the name matched. score is then 0.
+	nameOnly bool
+}
+
+// matchLocation picks the candidate whose addresses best match the stop.
+// ok is false when no candidate is good enough. tied lists the candidates
+// sharing the best score when there is more than one, in which case nothing
+// is picked.
+func matchLocation(stop model.CLStop, candidates []locations.Location) (best scoredLocation, tied []locations.Location, ok bool) {
+	if len(candidates) == 0 {
+		return scoredLocation{}, nil, false
+	}
+
+	if !hasStopAddress(stop) || !anyAddresses(candidates) {
+		if len(candidates) > 1 {
+			return scoredLocation{}, candidates, false
+		}
+		if hasStopAddress(stop) {
+			return scoredLocation{location: candidates[0], nameOnly: true}, nil, true
+		}
+		return scoredLocation{location: candidates[0], score: 1}, nil, true
+	}
+
+	scored := make([]scoredLocation, len(candidates))
+	for i, loc := range candidates {
+		scored[i] = scoredLocation{location: loc, score: scoreLocation(stop, loc)}
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+
+	best = scored[0]
+	if best.score < locationReviewThreshold {
+		return scoredLocation{}, nil, false
+	}
+	for _, s := range scored {
+		if s.score == best.score {
+			tied = append(tied, s.location)
+		}
+	}
+	if len(tied) > 1 {
+		return scoredLocation{}, tied, false
+	}
+	return best, nil, true
+}
+
+// scoreLocation returns the best score of any of the location's addresses.
+func scoreLocation(stop model.CLStop, loc locations.Location) float64 {
+	var best float64
+	for _, addr := range loc.Addresses {
+		if score := scoreAddress(stop, addr); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// scoreAddress rates from 0 to 1 how well addr matches the address fields the
+// stop gives. Streets earn partial credit for shared words; the other fields
+// match exactly after normalization.
+func scoreAddress(stop model.CLStop, addr locations.Address) float64 {
+	var total, got float64
+	if street := streetTokens(stop.AddressLine1); len(street) > 0 {
+		total += streetWeight
+		got += streetWeight * streetSimilarity(street, streetTokens(addr.Line1))
+	}
+	if city := normalizeWords(stop.City); city != "" {
+		total += cityWeight
+		if city == normalizeWords(addr.City) {
+			got += cityWeight
+		}
+	}
+	if state := normalizeState(stop.State); state != "" {
+		total += stateWeight
+		if state == normalizeState(addr.State) {
+			got += stateWeight
+		}
+	}
+	if zip := zip5(stop.Zipcode); zip != "" {
+		total += zipWeight
+		if zip == zip5(addr.Zip) {
+			got += zipWeight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return got / total
+}
+
+// streetSimilarity is the share of words two streets have in common. Streets
+// with different house numbers never match.
+func streetSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if isNumber(a[0]) && isNumber(b[0]) && a[0] != b[0] {
+		return 0
+	}
+	inA := map[string]bool{}
+	for _, t := range a {
+		inA[t] = true
+	}
+	union := len(inA)
+	shared := 0
+	seen := map[string]bool{}
+	for _, t := range b {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if inA[t] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
+
+// streetAbbreviations maps street words to their USPS abbreviations.
+var streetAbbreviations = map[string]string{
+	"street": "st", "avenue": "ave", "av": "ave", "road": "rd", "drive": "dr",
+	"boulevard": "blvd", "lane": "ln", "highway": "hwy", "parkway": "pkwy",
+	"court": "ct", "place": "pl", "circle": "cir", "suite": "ste", "building": "bldg",
+	"north": "n", "south": "s", "east": "e", "west": "w",
+	"northeast": "ne", "northwest": "nw", "southeast": "se", "southwest": "sw",
+}
+
+// streetTokens lower-cases a street line, splits it into words and
+// abbreviates them, so "12 Main Street." and "12 main st" agree.
+func streetTokens(line string) []string {
+	words := strings.Fields(normalizeWords(line))
+	for i, w := range words {
+		if abbr, ok := streetAbbreviations[w]; ok {
+			words[i] = abbr
+		}
+	}
+	return words
+}
+
+// normalizeWords lower-cases s and collapses punctuation and whitespace into
+// single spaces.
+func normalizeWords(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// normalizeState returns the lower-case two-letter code for a US state name
+// or code; other values are only normalized.
+func normalizeState(state string) string {
+	s := normalizeWords(state)
+	if code, ok := model.StateCode(s); ok {
+		return strings.ToLower(code)
+	}
+	return s
+}
+
+// zip5 returns the five-digit part of a US zip code, or the trimmed value.
+func zip5(zip string) string {
+	zip = strings.TrimSpace(zip)
+	if len(zip) > 5 && zip[5] == '-' {
+		return zip[:5]
+	}
+	return zip
+}
+
+func hasStopAddress(stop model.CLStop) bool {
+	return normalizeWords(stop.AddressLine1+stop.City+stop.State+stop.Zipcode) != ""
+}
+
+func anyAddresses(candidates []locations.Location) bool {
+	for _, loc := range candidates {
+		if len(loc.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/model"
+	locations "drumkit.com/interview/src/model/location"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreAddress(t *testing.T) {
+	addr := locations.Address{Line1: "1200 North Main Street", City: "Bentonville", State: "AR", Zip: "72712"}
+	for name, tc := range map[string]struct {
+		stop model.CLStop
+		want float64
+	}{
+		"exact after normalization": {model.CLStop{AddressLine1: "1200 N. Main St", City: "bentonville", State: "Arkansas", Zipcode: "72712-3148"}, 1},
+		"zip only":                  {model.CLStop{Zipcode: "72712"}, 1},
+		"wrong house number":        {model.CLStop{AddressLine1: "1300 N Main St", City: "Bentonville", State: "AR", Zipcode: "72712"}, 0.6},
+		"partial street":            {model.CLStop{AddressLine1: "1200 Main St", Zipcode: "72712"}, (0.4*0.75 + 0.3) / 0.7},
+		"different city and zip":    {model.CLStop{City: "Dallas", State: "AR", Zipcode: "75201"}, 0.25},
+		"no address":                {model.CLStop{}, 0},
+	} {
+		assert.InDelta(t, tc.want, scoreAddress(tc.stop, addr), 1e-9, name)
+	}
+}
+
+func TestMatchLocation(t *testing.T) {
+	bentonville := locations.Location{ID: 1, Name: "Walmart DC", Addresses: []locations.Address{{Line1: "1200 N Main St", City: "Bentonville", State: "AR", Zip: "72712"}}}
+	dallas := locations.Location{ID: 2, Name: "Walmart DC", Addresses: []locations.Address{{Line1: "500 Commerce St", City: "Dallas", State: "TX", Zip: "75201"}}}
+	bare := locations.Location{ID: 3, Name: "Walmart DC"}
+
+	t.Run("best address wins", func(t *testing.T) {
+		best, tied, ok := matchLocation(model.CLStop{AddressLine1: "500 Commerce Street", City: "Dallas", State: "TX"}, []locations.Location{bentonville, dallas})
+		require.True(t, ok)
+		assert.Empty(t, tied)
+		assert.Equal(t, 2, best.location.ID)
+		assert.Equal(t, 1.0, best.score)
+	})
+
+	t.Run("low confidence is still accepted", func(t *testing.T) {
+		best, _, ok := matchLocation(model.CLStop{AddressLine1: "1200 Main St", City: "Bentonville", Zipcode: "72799"}, []locations.Location{bentonville, dallas})
+		require.True(t, ok)
+		assert.Equal(t, 1, best.location.ID)
+		assert.Less(t, best.score, locationMatchThreshold)
+		assert.GreaterOrEqual(t, best.score, locationReviewThreshold)
+	})
+
+	t.Run("below threshold is no match", func(t *testing.T) {
+		_, _, ok := matchLocation(model.CLStop{AddressLine1: "77 Harbor Rd", City: "Houston", State: "TX", Zipcode: "77001"}, []locations.Location{bentonville, dallas})
+		assert.False(t, ok)
+	})
+
+	t.Run("ties are ambiguous", func(t *testing.T) {
+		twin := dallas
+		twin.ID = 4
+		_, tied, ok := matchLocation(model.CLStop{Zipcode: "75201"}, []locations.Location{dallas, twin})
+		assert.False(t, ok)
+		assert.Len(t, tied, 2)
+	})
+
+	t.Run("name only", func(t *testing.T) {
+		best, _, ok := matchLocation(model.CLStop{City: "Dallas"}, []locations.Location{bare})
+		require.True(t, ok)
+		assert.True(t, best.nameOnly)
+
+		_, tied, ok := matchLocation(model.CLStop{}, []locations.Location{bentonville, dallas})
+		assert.False(t, ok)
+		assert.Len(t, tied, 2)
+	})
+}
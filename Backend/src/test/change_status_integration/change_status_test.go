@@ -0,0 +1,124 @@
+package change_status_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/service"
+	"drumkit.com/interview/src/test/faketurvo"
+	logger "drumkit.com/interview/src/utils"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChangeStatusTestSuite struct {
+	suite.Suite
+	turvo   *faketurvo.Server
+	handler *handler.ChangeStatusHandler
+}
+
+func (suite *ChangeStatusTestSuite) SetupTest() {
+	logger.NewLogger()
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	gw := gateway.NewTurvoAPIGateway()
+	gw.Retry = gateway.RetryPolicy{MaxAttempts: 1}
+	svc := service.NewLoadService(gw)
+	suite.handler = &handler.ChangeStatusHandler{Service: svc}
+}
+
+func (suite *ChangeStatusTestSuite) seed(status model.ShipmentStatusCode) int {
+	return suite.turvo.AddShipment(model.ShipmentDetail{
+		Status: model.AvroStatus{Code: model.ValueKey{Key: string(status), Value: status.Name()}},
+	})
+}
+
+func (suite *ChangeStatusTestSuite) change(id int, body string) events.APIGatewayProxyResponse {
+	resp, err := suite.handler.ChangeStatusHandlerLambda(context.Background(), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"id": strconv.Itoa(id)},
+		Body:           body,
+	})
+	suite.Require().NoError(err)
+	return resp
+}
+
+func (suite *ChangeStatusTestSuite) TestCancelsWithReason() {
+	id := suite.seed(model.StatusCovered)
+
+	resp := suite.change(id, `{"status":"Canceled","reason":"customer pulled the freight"}`)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+
+	var load model.LoadDetail
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &load))
+	suite.Equal("Canceled", load.Status)
+
+	reqs := suite.turvo.RequestsTo("PUT", "/shipments/status/"+strconv.Itoa(id))
+	suite.Require().Len(reqs, 1)
+	var sent model.StatusUpdateRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &sent))
+	suite.Equal("2113", sent.Status.Code.Key)
+	suite.Equal("customer pulled the freight", sent.Status.Notes)
+}
+
+func (suite *ChangeStatusTestSuite) TestForwardsReasonForAnyTransition() {
+	id := suite.seed(model.StatusCovered)
+
+	resp := suite.change(id, `{"status":"Dispatched","reason":" driver confirmed "}`)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+
+	reqs := suite.turvo.RequestsTo("PUT", "/shipments/status/"+strconv.Itoa(id))
+	suite.Require().Len(reqs, 1)
+	var sent model.StatusUpdateRequest
+	suite.Require().NoError(json.Unmarshal(reqs[0].Body, &sent))
+	suite.Equal("driver confirmed", sent.Status.Notes)
+}
+
+func (suite *ChangeStatusTestSuite) TestPutsOnHoldWithReason() {
+	id := suite.seed(model.StatusDispatched)
+
+	resp := suite.change(id, `{"status":"On hold"}`)
+	suite.Equal(400, resp.StatusCode)
+	suite.Contains(resp.Body, "reason is required to move a load to On hold")
+	suite.Empty(suite.turvo.RequestsTo("PUT", "/shipments/status/"+strconv.Itoa(id)))
+
+	resp = suite.change(id, `{"status":"On hold","reason":"waiting on customs paperwork"}`)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+}
+
+func (suite *ChangeStatusTestSuite) TestRefusesIllegalTransition() {
+	id := suite.seed(model.StatusDelivered)
+
+	resp := suite.change(id, `{"status":"Tendered"}`)
+	suite.Equal(409, resp.StatusCode)
+
+	var body handler.ErrorBody
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &body))
+	suite.Equal("invalid_transition", body.Error.Code)
+	suite.Empty(suite.turvo.RequestsTo("PUT", "/shipments/status/"+strconv.Itoa(id)))
+}
+
+func (suite *ChangeStatusTestSuite) TestRejectsBadRequests() {
+	id := suite.seed(model.StatusCovered)
+	for _, body := range []string{
+		`{"status":"Lost"}`,
+		`{"status":"Canceled"}`,
+		`{"status":"Canceled","reason":"   "}`,
+		`not json`,
+	} {
+		resp := suite.change(id, body)
+		suite.Equal(400, resp.StatusCode, body)
+	}
+}
+
+func (suite *ChangeStatusTestSuite) TestUnknownLoad() {
+	resp := suite.change(424242, `{"status":"Dispatched"}`)
+	suite.Equal(404, resp.StatusCode)
+}
+
+func TestChangeStatusTestSuite(t *testing.T) {
+	suite.Run(t, new(ChangeStatusTestSuite))
+}
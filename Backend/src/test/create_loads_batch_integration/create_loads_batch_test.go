@@ -0,0 +1,107 @@
+package create_loads_batch_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
+	"drumkit.com/interview/src/service"
+	"drumkit.com/interview/src/test/faketurvo"
+	logger "drumkit.com/interview/src/utils"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+)
+
+type CreateLoadsBatchTestSuite struct {
+	suite.Suite
+	turvo   *faketurvo.Server
+	handler *handler.CreateLoadsBatchHandler
+}
+
+func (suite *CreateLoadsBatchTestSuite) SetupTest() {
+	logger.NewLogger()
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.turvo.AddLocation(locations.Location{Name: "Shipper"})
+	suite.turvo.AddLocation(locations.Location{Name: "Receiver"})
+	suite.turvo.AddCustomer(customer.Customer{Name: "Bunker"})
+	gw := gateway.NewTurvoAPIGateway()
+	gw.Retry = gateway.RetryPolicy{MaxAttempts: 1}
+	suite.handler = &handler.CreateLoadsBatchHandler{Service: service.NewLoadService(gw)}
+}
+
+func (suite *CreateLoadsBatchTestSuite) post(body string) (events.APIGatewayProxyResponse, handler.BatchResponse) {
+	resp, err := suite.handler.CreateLoadsBatchHandlerLambda(context.Background(), events.APIGatewayProxyRequest{Body: body})
+	suite.Require().NoError(err)
+	var batch handler.BatchResponse
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusMultiStatus {
+		suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &batch))
+	}
+	return resp, batch
+}
+
+const load = `{
+	"status": "Covered",
+	"customer": {"name": "Bunker"},
+	"pickup": {"name": "Shipper", "apptTime": "2025-08-01T08:00:00-05:00"},
+	"consignee": {"name": "Receiver", "apptTime": "2025-08-02T08:00:00-05:00"},
+	"rateData": {"customerLhRateUsd": 2000}
+}`
+
+func (suite *CreateLoadsBatchTestSuite) TestCreatesEveryLoad() {
+	resp, batch := suite.post(`[` + load + `,` + load + `]`)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, resp.Body)
+	suite.Equal(2, batch.Created)
+	suite.Zero(batch.Failed)
+	suite.Require().Len(batch.Results, 2)
+	for i, r := range batch.Results {
+		suite.Equal(i, r.Index)
+		suite.Equal(http.StatusCreated, r.Status)
+		suite.Nil(r.Error)
+		suite.Equal(fmt.Sprintf("FAKE-%d", r.Load.ID), r.Load.CustomID)
+	}
+	suite.ElementsMatch([]int{batch.Results[0].Load.ID, batch.Results[1].Load.ID}, suite.turvo.ShipmentIDs())
+}
+
+func (suite *CreateLoadsBatchTestSuite) TestReportsFailuresPerLoad() {
+	invalid := `{"status": "Covered", "customer": {"name": "Bunker"}, "pickup": {"name": "Shipper"}, "consignee": {"name": "Receiver", "apptTime": "2025-08-02T08:00:00Z"}}`
+	unknownCustomer := `{
+		"status": "Covered",
+		"customer": {"name": "Nobody"},
+		"pickup": {"name": "Shipper", "apptTime": "2025-08-01T08:00:00Z"},
+		"consignee": {"name": "Receiver", "apptTime": "2025-08-02T08:00:00Z"}
+	}`
+	resp, batch := suite.post(`[` + load + `,` + invalid + `,` + unknownCustomer + `]`)
+	suite.Require().Equal(http.StatusMultiStatus, resp.StatusCode, resp.Body)
+	suite.Equal(1, batch.Created)
+	suite.Equal(2, batch.Failed)
+	suite.Require().Len(batch.Results, 3)
+
+	suite.Equal(http.StatusCreated, batch.Results[0].Status)
+	suite.Equal([]int{batch.Results[0].Load.ID}, suite.turvo.ShipmentIDs())
+
+	suite.Equal(http.StatusUnprocessableEntity, batch.Results[1].Status)
+	suite.Equal("validation", batch.Results[1].Error.Code)
+	suite.Equal("pickup.apptTime", batch.Results[1].Error.Fields[0].Field)
+
+	suite.Equal(http.StatusUnprocessableEntity, batch.Results[2].Status)
+	suite.Empty(batch.Results[2].Error.Fields)
+	suite.Nil(batch.Results[2].Load)
+}
+
+func (suite *CreateLoadsBatchTestSuite) TestRejectsBadBatches() {
+	for _, body := range []string{`[]`, `{"loads": []}`, `not json`} {
+		resp, _ := suite.post(body)
+		suite.Equal(http.StatusBadRequest, resp.StatusCode, body)
+	}
+	suite.Empty(suite.turvo.ShipmentIDs())
+}
+
+func TestCreateLoadsBatchTestSuite(t *testing.T) {
+	suite.Run(t, new(CreateLoadsBatchTestSuite))
+}
@@ -1,12 +1,20 @@
 package create_loads_test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"drumkit.com/interview/src/gateway"
 	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/carrier"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
 	"drumkit.com/interview/src/service"
+	"drumkit.com/interview/src/test/faketurvo"
 	logger "drumkit.com/interview/src/utils"
+	"drumkit.com/interview/src/validation"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
@@ -14,15 +22,23 @@ import (
 
 type CreateLoadsTestSuite struct {
 	suite.Suite
+	turvo   *faketurvo.Server
 	handler *handler.CreateLoadsHandler
 }
 
-func (suite *CreateLoadsTestSuite) SetupSuite() {
+func (suite *CreateLoadsTestSuite) SetupTest() {
 	logger.NewLogger()
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.seed()
 	gw := gateway.NewTurvoAPIGateway()
 	svc := service.NewLoadService(gw)
 	suite.handler = &handler.CreateLoadsHandler{Service: svc}
-	// suite.T().Setenv("TOKEN", "test-token")
+}
+
+func (suite *CreateLoadsTestSuite) seed() {
+	suite.turvo.AddLocation(locations.Location{Name: "string"})
+	suite.turvo.AddCustomer(customer.Customer{Name: "string"})
+	suite.turvo.AddCarrier(carrier.Carrier{Name: "string", DotNumber: "string", McNumber: "string", Scac: "string"})
 }
 
 func (suite *CreateLoadsTestSuite) TestCreateLoadsHandlerLambda() {
@@ -70,12 +86,12 @@ func (suite *CreateLoadsTestSuite) TestCreateLoadsHandlerLambda() {
 				"contact": "string",
 				"phone": "string",
 				"email": "string",
-				"businessHours": "string",
+				"businessHours": "Mon-Fri 07:00-20:00",
 				"refNumber": "string",
 				"readyTime": "2025-08-19T23:31:45.558Z",
 				"apptTime": "2025-08-19T23:31:45.558Z",
 				"apptNote": "string",
-				"timezone": "string",
+				"timezone": "America/Chicago",
 				"warehouseId": "string"
 			},
 			"consignee": {
@@ -90,12 +106,12 @@ func (suite *CreateLoadsTestSuite) TestCreateLoadsHandlerLambda() {
 				"contact": "string",
 				"phone": "string",
 				"email": "string",
-				"businessHours": "string",
+				"businessHours": "Mon-Fri 07:00-20:00",
 				"refNumber": "string",
-				"mustDeliver": "string",
+				"mustDeliver": "2025-08-20",
 				"apptTime": "2025-08-19T23:31:45.558Z",
 				"apptNote": "string",
-				"timezone": "string",
+				"timezone": "America/Chicago",
 				"warehouseId": "string"
 			},
 			"carrier": {
@@ -128,12 +144,12 @@ func (suite *CreateLoadsTestSuite) TestCreateLoadsHandlerLambda() {
 				"externalTMSId": "string"
 			},
 			"rateData": {
-				"customerRateType": "string",
+				"customerRateType": "flat",
 				"customerNumHours": 0,
 				"customerLhRateUsd": 0,
 				"fscPercent": 0,
 				"fscPerMile": 0,
-				"carrierRateType": "string",
+				"carrierRateType": "flat",
 				"carrierNumHours": 0,
 				"carrierLhRateUsd": 0,
 				"carrierMaxRate": 0,
@@ -167,14 +183,47 @@ func (suite *CreateLoadsTestSuite) TestCreateLoadsHandlerLambda() {
 				"routeMiles": 0
 			}`,
 	}
-	resp, err := suite.handler.CreateLoadsHandlerLambda(req)
+	resp, err := suite.handler.CreateLoadsHandlerLambda(context.Background(), req)
 	if err != nil {
 		logger.Logger.Error("Error in CreateLoadsHandlerLambda:", zap.Error(err))
 	}
-	suite.Equal(200, resp.StatusCode, "expected status code 200")
+	suite.Equal(201, resp.StatusCode, "expected status code 201")
+	suite.Len(suite.turvo.ShipmentIDs(), 1)
+
+	var created model.CreatedLoad
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &created))
+	suite.Equal(suite.turvo.ShipmentIDs()[0], created.ID)
+	suite.NotEmpty(created.CustomID)
+	suite.Len(created.LocationIDs, 2)
+	suite.NotZero(created.CustomerID)
+	suite.NotZero(created.CarrierID)
 	logger.Logger.Info("CreateLoadsHandlerLambda executed successfully", zap.String("response", resp.Body))
 }
 
+func (suite *CreateLoadsTestSuite) TestRejectsInvalidFields() {
+	resp, err := suite.handler.CreateLoadsHandlerLambda(context.Background(), events.APIGatewayProxyRequest{Body: `{
+		"customer": {"name": "string"},
+		"pickup": {"name": "", "apptTime": "2025-08-02T10:00"},
+		"consignee": {"name": "string", "apptTime": "2025-08-01T10:00"},
+		"rateData": {"fscPercent": -1},
+		"totalWeight": -100
+	}`})
+	suite.Require().NoError(err)
+	suite.Equal(422, resp.StatusCode)
+
+	var body handler.ErrorBody
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &body))
+	suite.Equal("validation", body.Error.Code)
+	suite.Equal([]validation.FieldError{
+		{Field: "status", Message: "is required"},
+		{Field: "pickup.name", Message: "is required"},
+		{Field: "consignee.apptTime", Message: "is before the appointment at pickup"},
+		{Field: "totalWeight", Message: "cannot be negative"},
+		{Field: "rateData.fscPercent", Message: "cannot be negative"},
+	}, body.Error.Fields)
+	suite.Empty(suite.turvo.ShipmentIDs())
+}
+
 func TestCreateLoadsTestSuite(t *testing.T) {
 	suite.Run(t, new(CreateLoadsTestSuite))
 }
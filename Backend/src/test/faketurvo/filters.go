@@ -0,0 +1,135 @@
+package faketurvo
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// shipmentFields maps the filter and sort fields accepted by /shipments/list
+// to the document paths they read. Dates are compared as RFC 3339 strings.
+var shipmentFields = map[string]string{
+	"status":       "status.code.key",
+	"customerId":   "customerOrder.customer.id",
+	"carrierId":    "carrierOrder.carrier.id",
+	"pickupDate":   "startDate.date",
+	"deliveryDate": "endDate.date",
+	"updated":      "updated",
+	"created":      "created",
+	"customId":     "customId",
+	"lane.start":   "lane.start",
+	"lane.end":     "lane.end",
+}
+
+// filterShipments applies field[op]=value filters and sortBy/sortDirection.
+func filterShipments(docs []map[string]interface{}, q url.Values) ([]map[string]interface{}, error) {
+	var out []map[string]interface{}
+	for _, doc := range docs {
+		ok, err := matchesAll(doc, q)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, doc)
+		}
+	}
+
+	if field := q.Get("sortBy"); field != "" {
+		path, ok := shipmentFields[field]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by %q", field)
+		}
+		desc := q.Get("sortDirection") == "desc"
+		sort.SliceStable(out, func(i, j int) bool {
+			a, b := firstValue(out[i], path), firstValue(out[j], path)
+			if desc {
+				return a > b
+			}
+			return a < b
+		})
+	}
+	return out, nil
+}
+
+func matchesAll(doc map[string]interface{}, q url.Values) (bool, error) {
+	for key, values := range q {
+		open := strings.Index(key, "[")
+		if open < 0 || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		field, op := key[:open], key[open+1:len(key)-1]
+		path, ok := shipmentFields[field]
+		if !ok {
+			return false, fmt.Errorf("unknown filter field %q", field)
+		}
+		if !matches(lookup(doc, path), op, values[0]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matches(actual []string, op, want string) bool {
+	for _, a := range actual {
+		switch op {
+		case "eq":
+			if strings.EqualFold(a, want) {
+				return true
+			}
+		case "in":
+			for _, w := range strings.Split(strings.Trim(want, "[]"), ",") {
+				if strings.EqualFold(a, strings.TrimSpace(w)) {
+					return true
+				}
+			}
+		case "gte":
+			if a >= want {
+				return true
+			}
+		case "lte":
+			if a <= want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookup returns the string values found at a dotted path, flattening arrays.
+func lookup(v interface{}, path string) []string {
+	if path == "" {
+		switch x := v.(type) {
+		case nil:
+			return nil
+		case string:
+			return []string{x}
+		case float64:
+			return []string{fmt.Sprintf("%d", int64(x))}
+		default:
+			return []string{fmt.Sprint(x)}
+		}
+	}
+	head, rest := path, ""
+	if i := strings.Index(path, "."); i >= 0 {
+		head, rest = path[:i], path[i+1:]
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return lookup(x[head], rest)
+	case []interface{}:
+		var out []string
+		for _, item := range x {
+			out = append(out, lookup(item, path)...)
+		}
+		return out
+	}
+	return nil
+}
+
+func firstValue(doc map[string]interface{}, path string) string {
+	if values := lookup(doc, path); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
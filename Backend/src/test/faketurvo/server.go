@@ -0,0 +1,746 @@
+// Package faketurvo is an in-memory stand-in for the Turvo public API. It keeps
+// shipments, locations, customers and carriers in memory, records every request it
+// receives and can be told to fail specific endpoints, so the gateway, service
+// and handlers can be tested without network access.
+package faketurvo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"drumkit.com/interview/src/model/carrier"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
+)
+
+// Default credentials accepted by a new Server. SetEnv exports them as TURVO_* variables.
+const (
+	APIKey       = "fake-api-key"
+	ClientID     = "publicapi"
+	ClientSecret = "secret"
+	Username     = "dispatcher@drumkit.test"
+	Password     = "password"
+)
+
+// apiPrefix mirrors the version prefix of the real Turvo base URL.
+const apiPrefix = "/v1"
+
+// Request is a copy of an HTTP request received by the fake.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Failure makes matching requests fail instead of reaching the normal handler.
+type Failure struct {
+	// Method and Path select the requests to fail. Empty Method matches any
+	// method; Path is compared without the /v1 prefix, e.g. "/shipments/list".
+	Method string
+	Path   string
+	// Status and Body are written as the response. Header is added to it.
+	Status int
+	Body   string
+	Header http.Header
+	// Drop closes the connection without a response to simulate a network error.
+	Drop bool
+	// Delay is slept before responding.
+	Delay time.Duration
+	// Times is how many requests fail before the endpoint recovers. Zero means every request.
+	Times int
+}
+
+// Server is a fake Turvo API backed by httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	// TokenTTL is reported as expires_in for newly issued tokens.
+	TokenTTL time.Duration
+
+	mu         sync.Mutex
+	nextID     int
+	tokens     map[string]bool
+	shipments  map[int]map[string]interface{}
+	locations  map[int]locations.Location
+	customers  map[int]customer.Customer
+	carriers   map[int]carrier.Carrier
+	failures   []*Failure
+	requests   []Request
+	tokenCount int
+}
+
+// New starts a fake Turvo server. Call Close when done.
+func New() *Server {
+	s := &Server{
+		TokenTTL:  time.Hour,
+		nextID:    1000,
+		tokens:    map[string]bool{},
+		shipments: map[int]map[string]interface{}{},
+		locations: map[int]locations.Location{},
+		customers: map[int]customer.Customer{},
+		carriers:  map[int]carrier.Carrier{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewForTest starts a fake Turvo server, points the TURVO_* environment
+// variables at it and closes it when the test finishes.
+func NewForTest(t testing.TB) *Server {
+	s := New()
+	s.SetEnv(t)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// BaseURL is the value to use as TURVO_BASE_URL.
+func (s *Server) BaseURL() string {
+	return s.URL + apiPrefix
+}
+
+// SetEnv points the TURVO_* environment variables at the fake for the
+// duration of the test.
+func (s *Server) SetEnv(t testing.TB) {
+	t.Setenv("TURVO_BASE_URL", s.BaseURL())
+	t.Setenv("TURVO_API_KEY", APIKey)
+	t.Setenv("TURVO_CLIENT_ID", ClientID)
+	t.Setenv("TURVO_CLIENT_SECRET", ClientSecret)
+	t.Setenv("TURVO_USERNAME", Username)
+	t.Setenv("TURVO_PASSWORD", Password)
+}
+
+// AddLocation stores loc, assigning an ID when it has none, and returns the ID.
+func (s *Server) AddLocation(loc locations.Location) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if loc.ID == 0 {
+		loc.ID = s.allocateID()
+	}
+	s.locations[loc.ID] = loc
+	return loc.ID
+}
+
+// AddCustomer stores c, assigning an ID when it has none, and returns the ID.
+func (s *Server) AddCustomer(c customer.Customer) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.ID == 0 {
+		c.ID = s.allocateID()
+	}
+	s.customers[c.ID] = c
+	return c.ID
+}
+
+// AddCarrier stores c, assigning an ID when it has none, and returns the ID.
+func (s *Server) AddCarrier(c carrier.Carrier) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.ID == 0 {
+		c.ID = s.allocateID()
+	}
+	s.carriers[c.ID] = c
+	return c.ID
+}
+
+// AddShipment stores shipment (any value that marshals to a Turvo shipment
+// object), assigning an ID when it has none, and returns the ID.
+func (s *Server) AddShipment(shipment interface{}) int {
+	doc := toDocument(shipment)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.storeShipment(doc)
+}
+
+// Shipment returns the stored shipment document with the given ID.
+func (s *Server) Shipment(id int) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc, ok := s.shipments[id]
+	return doc, ok
+}
+
+// ShipmentIDs returns the IDs of all stored shipments in ascending order.
+func (s *Server) ShipmentIDs() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedShipmentIDs()
+}
+
+// Inject registers a failure. Failures are matched in the order they were added.
+func (s *Server) Inject(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// ClearFailures removes all injected failures.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// RevokeTokens invalidates every issued token, as if they had expired.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]bool{}
+}
+
+// TokenRequests reports how many tokens have been issued.
+func (s *Server) TokenRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokenCount
+}
+
+// Requests returns every request received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Request, len(s.requests))
+	copy(out, s.requests)
+	return out
+}
+
+// RequestsTo returns the recorded requests for method and path (without the /v1 prefix).
+func (s *Server) RequestsTo(method, path string) []Request {
+	var out []Request
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == path {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ResetRequests clears the request log.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	path := strings.TrimPrefix(req.URL.Path, apiPrefix)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: req.Method,
+		Path:   path,
+		Query:  req.URL.Query(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	failure := s.matchFailure(req.Method, path)
+	s.mu.Unlock()
+
+	if failure != nil {
+		s.fail(w, req, failure)
+		return
+	}
+
+	if path == "/oauth/token" && req.Method == http.MethodPost {
+		s.issueToken(w, req, body)
+		return
+	}
+	if !s.authorized(req) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token", "error_description": "Invalid access token"})
+		return
+	}
+	s.route(w, req, path, body)
+}
+
+// shipmentPath matches /shipments/{id}.
+var shipmentPath = regexp.MustCompile(`^/shipments/(\d+)$`)
+
+// locationPath matches /locations/{id}.
+var locationPath = regexp.MustCompile(`^/locations/(\d+)$`)
+
+// shipmentStatusPath matches /shipments/status/{id}.
+var shipmentStatusPath = regexp.MustCompile(`^/shipments/status/(\d+)$`)
+
+func (s *Server) route(w http.ResponseWriter, req *http.Request, path string, body []byte) {
+	switch {
+	case path == "/shipments/list" && req.Method == http.MethodGet:
+		s.listShipments(w, req)
+	case path == "/shipments" && req.Method == http.MethodPost:
+		s.createShipment(w, body)
+	case path == "/locations/list" && req.Method == http.MethodGet:
+		s.listLocations(w, req)
+	case path == "/locations" && req.Method == http.MethodPost:
+		s.createLocation(w, body)
+	case locationPath.MatchString(path) && req.Method == http.MethodGet:
+		id, _ := strconv.Atoi(locationPath.FindStringSubmatch(path)[1])
+		s.getLocation(w, id)
+	case path == "/customers/list" && req.Method == http.MethodGet:
+		s.listCustomers(w, req)
+	case path == "/carriers/list" && req.Method == http.MethodGet:
+		s.listCarriers(w, req)
+	case shipmentPath.MatchString(path) && req.Method == http.MethodGet:
+		id, _ := strconv.Atoi(shipmentPath.FindStringSubmatch(path)[1])
+		s.getShipment(w, id)
+	case shipmentPath.MatchString(path) && req.Method == http.MethodPut:
+		id, _ := strconv.Atoi(shipmentPath.FindStringSubmatch(path)[1])
+		s.updateShipment(w, id, body)
+	case shipmentStatusPath.MatchString(path) && req.Method == http.MethodPut:
+		id, _ := strconv.Atoi(shipmentStatusPath.FindStringSubmatch(path)[1])
+		s.updateShipmentStatus(w, id, body)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no route for %s %s", req.Method, path))
+	}
+}
+
+func (s *Server) issueToken(w http.ResponseWriter, req *http.Request, body []byte) {
+	q := req.URL.Query()
+	var creds struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	_ = json.Unmarshal(body, &creds)
+	if req.Header.Get("x-api-key") != APIKey || q.Get("client_id") != ClientID || q.Get("client_secret") != ClientSecret ||
+		creds.Username != Username || creds.Password != Password {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_grant", "error_description": "Bad credentials"})
+		return
+	}
+
+	s.mu.Lock()
+	s.tokenCount++
+	token := fmt.Sprintf("fake-token-%d", s.tokenCount)
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "bearer",
+		"expires_in":   int(s.TokenTTL / time.Second),
+		"scope":        "read trust write",
+	})
+}
+
+func (s *Server) authorized(req *http.Request) bool {
+	if req.Header.Get("x-api-key") != APIKey {
+		return false
+	}
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[token]
+}
+
+func (s *Server) listShipments(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	ids := s.sortedShipmentIDs()
+	docs := make([]map[string]interface{}, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- { // newest first, like Turvo
+		docs = append(docs, s.shipments[ids[i]])
+	}
+	s.mu.Unlock()
+
+	docs, err := filterShipments(docs, req.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, pagination, ok := paginate(w, req, docs)
+	if !ok {
+		return
+	}
+	writeSuccess(w, http.StatusOK, map[string]interface{}{
+		"pagination": pagination,
+		"shipments":  page,
+	})
+}
+
+func (s *Server) createShipment(w http.ResponseWriter, body []byte) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed shipment JSON: "+err.Error())
+		return
+	}
+	if _, ok := doc["globalRoute"]; !ok {
+		writeError(w, http.StatusBadRequest, "globalRoute is required")
+		return
+	}
+
+	s.mu.Lock()
+	s.storeShipment(doc)
+	s.mu.Unlock()
+
+	writeSuccess(w, http.StatusOK, doc)
+}
+
+func (s *Server) getShipment(w http.ResponseWriter, id int) {
+	s.mu.Lock()
+	doc, ok := s.shipments[id]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("shipment %d not found", id))
+		return
+	}
+	writeSuccess(w, http.StatusOK, doc)
+}
+
+func (s *Server) listLocations(w http.ResponseWriter, req *http.Request) {
+	names, filtered := inFilter(req.URL.Query().Get("name[in]"))
+
+	s.mu.Lock()
+	var out []locations.Location
+	for _, id := range sortedKeys(s.locations) {
+		loc := s.locations[id]
+		if !filtered || names[strings.ToLower(loc.Name)] {
+			out = append(out, loc)
+		}
+	}
+	s.mu.Unlock()
+
+	page, pagination, ok := paginate(w, req, out)
+	if !ok {
+		return
+	}
+	writeSuccess(w, http.StatusOK, map[string]interface{}{
+		"pagination": pagination,
+		"locations":  page,
+	})
+}
+
+func (s *Server) getLocation(w http.ResponseWriter, id int) {
+	s.mu.Lock()
+	loc, ok := s.locations[id]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("location %d not found", id))
+		return
+	}
+	writeSuccess(w, http.StatusOK, loc)
+}
+
+func (s *Server) createLocation(w http.ResponseWriter, body []byte) {
+	var loc locations.Location
+	if err := json.Unmarshal(body, &loc); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed location JSON: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(loc.Name) == "" || len(loc.Addresses) == 0 {
+		writeError(w, http.StatusBadRequest, "name and addresses are required")
+		return
+	}
+
+	s.mu.Lock()
+	loc.ID = s.allocateID()
+	s.locations[loc.ID] = loc
+	s.mu.Unlock()
+
+	writeSuccess(w, http.StatusOK, loc)
+}
+
+// listCustomers supports the name[in], id[eq], externalIds.value[eq],
+// address.city[eq], address.state[eq] and address.zip[eq] filters.
+func (s *Server) listCustomers(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	names, filtered := inFilter(q.Get("name[in]"))
+	matches := func(c customer.Customer) bool {
+		if filtered && !names[strings.ToLower(c.Name)] {
+			return false
+		}
+		if id, ok := q["id[eq]"]; ok && id[0] != strconv.Itoa(c.ID) {
+			return false
+		}
+		if want, ok := q["externalIds.value[eq]"]; ok {
+			found := false
+			for _, ext := range c.ExternalIds {
+				found = found || (!ext.Deleted && strings.EqualFold(ext.Value, want[0]))
+			}
+			if !found {
+				return false
+			}
+		}
+		city, byCity := q["address.city[eq]"]
+		state, byState := q["address.state[eq]"]
+		zip, byZip := q["address.zip[eq]"]
+		if byCity || byState || byZip {
+			found := false
+			for _, addr := range c.Address {
+				found = found || (!addr.Deleted &&
+					(!byCity || strings.EqualFold(addr.City, city[0])) &&
+					(!byState || strings.EqualFold(addr.State, state[0])) &&
+					(!byZip || addr.Zip == zip[0]))
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	s.mu.Lock()
+	var out []customer.Customer
+	for _, id := range sortedKeys(s.customers) {
+		if c := s.customers[id]; matches(c) {
+			out = append(out, c)
+		}
+	}
+	s.mu.Unlock()
+
+	page, pagination, ok := paginate(w, req, out)
+	if !ok {
+		return
+	}
+	writeSuccess(w, http.StatusOK, map[string]interface{}{
+		"pagination": pagination,
+		"customers":  page,
+	})
+}
+
+// listCarriers supports the id[eq], mcNumber[eq], dotNumber[eq] and scac[eq]
+// filters.
+func (s *Server) listCarriers(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	matches := func(c carrier.Carrier) bool {
+		if id, ok := q["id[eq]"]; ok && id[0] != strconv.Itoa(c.ID) {
+			return false
+		}
+		for key, value := range map[string]string{
+			"mcNumber[eq]":  c.McNumber,
+			"dotNumber[eq]": c.DotNumber,
+			"scac[eq]":      c.Scac,
+		} {
+			if want, ok := q[key]; ok && !strings.EqualFold(want[0], value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	s.mu.Lock()
+	var out []carrier.Carrier
+	for _, id := range sortedKeys(s.carriers) {
+		if c := s.carriers[id]; matches(c) {
+			out = append(out, c)
+		}
+	}
+	s.mu.Unlock()
+
+	page, pagination, ok := paginate(w, req, out)
+	if !ok {
+		return
+	}
+	writeSuccess(w, http.StatusOK, map[string]interface{}{
+		"pagination": pagination,
+		"carriers":   page,
+	})
+}
+
+// storeShipment assigns IDs to the shipment and its array entries, and a
+// customId and timestamps when missing. s.mu must be held.
+func (s *Server) storeShipment(doc map[string]interface{}) int {
+	id := intField(doc, "id")
+	if id == 0 {
+		id = s.allocateID()
+		doc["id"] = id
+	}
+	s.assignIDs(doc)
+	if _, ok := doc["customId"]; !ok {
+		doc["customId"] = fmt.Sprintf("FAKE-%d", id)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, key := range []string{"created", "createdDate"} {
+		if _, ok := doc[key]; !ok {
+			doc[key] = now
+		}
+	}
+	doc["updated"] = now
+	doc["lastUpdatedOn"] = now
+	s.shipments[id] = doc
+	return id
+}
+
+// allocateID returns a fresh ID. s.mu must be held.
+func (s *Server) allocateID() int {
+	s.nextID++
+	return s.nextID
+}
+
+// sortedShipmentIDs returns shipment IDs in ascending order. s.mu must be held.
+func (s *Server) sortedShipmentIDs() []int {
+	ids := make([]int, 0, len(s.shipments))
+	for id := range s.shipments {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// matchFailure returns the first active failure for the request and consumes
+// one of its uses. s.mu must be held.
+func (s *Server) matchFailure(method, path string) *Failure {
+	for i, f := range s.failures {
+		if (f.Method != "" && f.Method != method) || f.Path != path {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i:i], s.failures[i+1:]...)
+			}
+		}
+		copied := *f
+		return &copied
+	}
+	return nil
+}
+
+func (s *Server) fail(w http.ResponseWriter, req *http.Request, f *Failure) {
+	if f.Delay > 0 {
+		select {
+		case <-time.After(f.Delay):
+		case <-req.Context().Done():
+			return
+		}
+	}
+	if f.Drop {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	for key, values := range f.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	status := f.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	io.WriteString(w, f.Body)
+}
+
+// paginate applies Turvo's start/pageSize parameters to items.
+func paginate[T any](w http.ResponseWriter, req *http.Request, items []T) ([]T, map[string]interface{}, bool) {
+	q := req.URL.Query()
+	start, pageSize := 0, 24
+	var err error
+	if v := q.Get("start"); v != "" {
+		if start, err = strconv.Atoi(v); err != nil || start < 0 {
+			writeError(w, http.StatusBadRequest, "start must be a non-negative integer")
+			return nil, nil, false
+		}
+	}
+	if v := q.Get("lastObjectKey"); v != "" {
+		if start, err = strconv.Atoi(v); err != nil || start < 0 {
+			writeError(w, http.StatusBadRequest, "unknown lastObjectKey")
+			return nil, nil, false
+		}
+	}
+	if v := q.Get("pageSize"); v != "" {
+		if pageSize, err = strconv.Atoi(v); err != nil || pageSize <= 0 {
+			writeError(w, http.StatusBadRequest, "pageSize must be a positive integer")
+			return nil, nil, false
+		}
+	}
+
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	page := items[start:end]
+	if page == nil {
+		page = []T{}
+	}
+
+	pagination := map[string]interface{}{
+		"start":              start,
+		"pageSize":           pageSize,
+		"totalRecordsInPage": len(page),
+		"moreAvailable":      end < len(items),
+	}
+	if end < len(items) {
+		pagination["lastObjectKey"] = strconv.Itoa(end)
+	}
+	return page, pagination, true
+}
+
+// inFilter parses Turvo's "[a,b]" list syntax into a lower-cased set.
+func inFilter(raw string) (map[string]bool, bool) {
+	if raw == "" {
+		return nil, false
+	}
+	set := map[string]bool{}
+	for _, v := range strings.Split(strings.Trim(raw, "[]"), ",") {
+		set[strings.ToLower(strings.TrimSpace(v))] = true
+	}
+	return set, true
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func toDocument(v interface{}) map[string]interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("faketurvo: cannot marshal shipment: %v", err))
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		panic(fmt.Sprintf("faketurvo: shipment is not a JSON object: %v", err))
+	}
+	return doc
+}
+
+func intField(doc map[string]interface{}, key string) int {
+	switch v := doc[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func writeSuccess(w http.ResponseWriter, status int, details interface{}) {
+	writeJSON(w, status, map[string]interface{}{"Status": "SUCCESS", "details": details})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"Status":  "ERROR",
+		"details": map[string]interface{}{"errorMessage": message, "errorCode": strconv.Itoa(status)},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,177 @@
+package faketurvo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Turvo _operation values on array entries in an update.
+const (
+	opInsert = 0
+	opUpdate = 1
+	opDelete = 2
+)
+
+func (s *Server) updateShipment(w http.ResponseWriter, id int, body []byte) {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(body, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed shipment JSON: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.shipments[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("shipment %d not found", id))
+		return
+	}
+	// merge into a copy so responses already being written keep the old document
+	updated := toDocument(doc)
+	delete(patch, "id")
+	if err := s.merge(updated, patch); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	updated["updated"] = now
+	updated["lastUpdatedOn"] = now
+	s.shipments[id] = updated
+
+	writeSuccess(w, http.StatusOK, updated)
+}
+
+// updateShipmentStatus replaces the shipment's status. Like the fake's other
+// endpoints it trusts the caller; transition rules are enforced by Drumkit.
+func (s *Server) updateShipmentStatus(w http.ResponseWriter, id int, body []byte) {
+	var req struct {
+		Status map[string]interface{} `json:"status"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.Status == nil {
+		writeError(w, http.StatusBadRequest, "status is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.shipments[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("shipment %d not found", id))
+		return
+	}
+	updated := toDocument(doc)
+	updated["status"] = req.Status
+	now := time.Now().UTC().Format(time.RFC3339)
+	updated["updated"] = now
+	updated["lastUpdatedOn"] = now
+	s.shipments[id] = updated
+
+	writeSuccess(w, http.StatusOK, updated)
+}
+
+// merge applies patch to doc the way Turvo applies a shipment update: objects
+// merge field by field and arrays of objects are changed entry by entry
+// according to each entry's _operation. s.mu must be held.
+func (s *Server) merge(doc, patch map[string]interface{}) error {
+	for key, value := range patch {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			existing, ok := doc[key].(map[string]interface{})
+			if !ok {
+				existing = map[string]interface{}{}
+				doc[key] = existing
+			}
+			if err := s.merge(existing, v); err != nil {
+				return fmt.Errorf("%s.%w", key, err)
+			}
+		case []interface{}:
+			if !hasOperations(v) {
+				doc[key] = v
+				continue
+			}
+			existing, _ := doc[key].([]interface{})
+			merged, err := s.mergeEntries(existing, v)
+			if err != nil {
+				return fmt.Errorf("%s%w", key, err)
+			}
+			doc[key] = merged
+		default:
+			doc[key] = v
+		}
+	}
+	return nil
+}
+
+func (s *Server) mergeEntries(existing, changes []interface{}) ([]interface{}, error) {
+	out := append([]interface{}(nil), existing...)
+	for _, c := range changes {
+		change := c.(map[string]interface{})
+		op := intField(change, "_operation")
+		delete(change, "_operation")
+		if op == opInsert {
+			s.assignIDs(change)
+			out = append(out, change)
+			continue
+		}
+
+		id := intField(change, "id")
+		i := indexOfID(out, id)
+		if i < 0 {
+			return nil, fmt.Errorf(": no entry with id %d", id)
+		}
+		switch op {
+		case opUpdate:
+			if err := s.merge(out[i].(map[string]interface{}), change); err != nil {
+				return nil, fmt.Errorf("[%d].%w", id, err)
+			}
+		case opDelete:
+			out = append(out[:i], out[i+1:]...)
+		default:
+			return nil, fmt.Errorf("[%d]: unknown _operation %d", id, op)
+		}
+	}
+	return out, nil
+}
+
+// assignIDs gives every object inside an array of v a fresh ID when it has
+// none, and drops the _operation markers Turvo does not store. s.mu must be held.
+func (s *Server) assignIDs(v interface{}) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		delete(x, "_operation")
+		for _, child := range x {
+			s.assignIDs(child)
+		}
+	case []interface{}:
+		for _, item := range x {
+			if obj, ok := item.(map[string]interface{}); ok && intField(obj, "id") == 0 {
+				obj["id"] = s.allocateID()
+			}
+			s.assignIDs(item)
+		}
+	}
+}
+
+func hasOperations(entries []interface{}) bool {
+	for _, e := range entries {
+		if obj, ok := e.(map[string]interface{}); ok {
+			if _, ok := obj["_operation"]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func indexOfID(entries []interface{}, id int) int {
+	for i, e := range entries {
+		if obj, ok := e.(map[string]interface{}); ok && intField(obj, "id") == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,83 @@
+package get_load_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/service"
+	"drumkit.com/interview/src/test/faketurvo"
+	logger "drumkit.com/interview/src/utils"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+)
+
+type GetLoadTestSuite struct {
+	suite.Suite
+	turvo   *faketurvo.Server
+	handler *handler.GetLoadHandler
+}
+
+func (suite *GetLoadTestSuite) SetupTest() {
+	logger.NewLogger()
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	gw := gateway.NewTurvoAPIGateway()
+	gw.Retry = gateway.RetryPolicy{MaxAttempts: 1}
+	svc := service.NewLoadService(gw)
+	suite.handler = &handler.GetLoadHandler{Service: svc}
+}
+
+func (suite *GetLoadTestSuite) TestReturnsLoadInDrumkitFormat() {
+	id := suite.turvo.AddShipment(model.ShipmentDetail{
+		CustomID: "M-77",
+		Status:   model.AvroStatus{Code: model.ValueKey{Key: "2101", Value: "Tendered"}},
+		GlobalRoute: []model.RouteStop{
+			{GlobalRoute: model.GlobalRoute{Name: "Shipper", StopType: model.ValueKey{Key: "1500", Value: "Pickup"}}},
+			{GlobalRoute: model.GlobalRoute{Name: "Receiver", StopType: model.ValueKey{Key: "1501", Value: "Delivery"}}},
+		},
+		CustomerOrder: []model.CustomerOrderDetail{
+			{CustomerOrderAvro: model.CustomerOrderAvro{Customer: model.CustomerAvro{ID: 973069, Name: "Bunker"}}},
+		},
+	})
+
+	resp, err := suite.handler.GetLoadHandlerLambda(context.Background(), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"id": strconv.Itoa(id)},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+
+	var load model.LoadDetail
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &load))
+	suite.Equal(id, load.ID)
+	suite.Equal("M-77", load.CustomID)
+	suite.Equal("Tendered", load.Status)
+	suite.Equal("Bunker", load.Customer.Name)
+	suite.Equal("Shipper", load.Pickup.Name)
+	suite.Equal("Receiver", load.Consignee.Name)
+}
+
+func (suite *GetLoadTestSuite) TestUnknownLoad() {
+	resp, err := suite.handler.GetLoadHandlerLambda(context.Background(), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"id": "424242"},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(404, resp.StatusCode)
+}
+
+func (suite *GetLoadTestSuite) TestRejectsBadID() {
+	for _, id := range []string{"", "abc", "0", "-3"} {
+		resp, err := suite.handler.GetLoadHandlerLambda(context.Background(), events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{"id": id},
+		})
+		suite.Require().NoError(err)
+		suite.Equal(400, resp.StatusCode, "id %q", id)
+	}
+}
+
+func TestGetLoadTestSuite(t *testing.T) {
+	suite.Run(t, new(GetLoadTestSuite))
+}
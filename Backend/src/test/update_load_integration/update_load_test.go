@@ -0,0 +1,150 @@
+package update_load_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"drumkit.com/interview/src/gateway"
+	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/model/customer"
+	locations "drumkit.com/interview/src/model/location"
+	"drumkit.com/interview/src/service"
+	"drumkit.com/interview/src/test/faketurvo"
+	logger "drumkit.com/interview/src/utils"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+)
+
+type UpdateLoadTestSuite struct {
+	suite.Suite
+	turvo   *faketurvo.Server
+	handler *handler.UpdateLoadHandler
+	id      int
+}
+
+func (suite *UpdateLoadTestSuite) SetupTest() {
+	logger.NewLogger()
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.turvo.AddLocation(locations.Location{Name: "Shipper", Notes: "Business hours: Mon-Fri 07:00-16:00\nRef #: 12"})
+	suite.turvo.AddLocation(locations.Location{Name: "Receiver"})
+	suite.turvo.AddCustomer(customer.Customer{ID: 973069, Name: "Bunker"})
+
+	gw := gateway.NewTurvoAPIGateway()
+	gw.Retry = gateway.RetryPolicy{MaxAttempts: 1}
+	svc := service.NewLoadService(gw)
+	suite.handler = &handler.UpdateLoadHandler{Service: svc}
+
+	created, err := svc.CreateLoad(context.Background(), model.CreateLoadRequest{
+		Status:    "Covered",
+		Customer:  model.CLCustomer{ExternalTMSId: "973069", Name: "Bunker"},
+		Pickup:    model.CLPickup{Name: "Shipper", ApptTime: "2025-08-01T13:00:00Z", Timezone: "America/Chicago"},
+		Consignee: model.CLConsignee{Name: "Receiver", ApptTime: "2025-08-03T15:00:00Z"},
+		RateData:  model.CLRateData{CustomerLhRateUsd: 2000},
+		PoNums:    "PO-1",
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal([]int{created.ID}, suite.turvo.ShipmentIDs())
+	suite.id = created.ID
+	suite.turvo.ResetRequests()
+}
+
+func (suite *UpdateLoadTestSuite) update(body string) events.APIGatewayProxyResponse {
+	resp, err := suite.handler.UpdateLoadHandlerLambda(context.Background(), events.APIGatewayProxyRequest{
+		PathParameters: map[string]string{"id": strconv.Itoa(suite.id)},
+		Body:           body,
+	})
+	suite.Require().NoError(err)
+	return resp
+}
+
+func (suite *UpdateLoadTestSuite) TestSendsOnlyChangedSections() {
+	resp := suite.update(`{"pickup":{"apptTime":"2025-08-01T15:00:00Z"},"rateData":{"customerLhRateUsd":2400}}`)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+
+	var load model.LoadDetail
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &load))
+	suite.Equal("2025-08-01T10:00:00-05:00", load.Pickup.ApptTime)
+	suite.Equal(2400.0, load.RateData.CustomerLhRateUsd)
+	suite.Equal("PO-1", load.PoNums)
+
+	puts := suite.turvo.RequestsTo("PUT", "/shipments/"+strconv.Itoa(suite.id))
+	suite.Require().Len(puts, 1)
+	var sent map[string]json.RawMessage
+	suite.Require().NoError(json.Unmarshal(puts[0].Body, &sent))
+	suite.ElementsMatch([]string{"startDate", "globalRoute", "customerOrder"}, keys(sent))
+}
+
+func (suite *UpdateLoadTestSuite) TestUnchangedBodySkipsTurvo() {
+	resp := suite.update(`{"status":"Covered"}`)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	suite.Empty(suite.turvo.RequestsTo("PUT", "/shipments/"+strconv.Itoa(suite.id)))
+}
+
+func (suite *UpdateLoadTestSuite) TestRejectsReadOnlyChange() {
+	resp := suite.update(`{"customer":{"name":"Someone Else"}}`)
+	suite.Equal(422, resp.StatusCode)
+}
+
+func (suite *UpdateLoadTestSuite) TestValidatesThePatchedLoad() {
+	for body, field := range map[string]string{
+		`{"totalWeight":-5}`: "totalWeight",
+		`{"consignee":{"apptTime":"2025-07-31T15:00:00Z"}}`:                   "stops[1].apptTime",
+		`{"pickup":{"readyTime":"2025-08-01T14:00:00Z"}}`:                     "stops[0].readyTime",
+		`{"rateData":{"customerRateType":"perMile","customerLhRateUsd":2.5}}`: "routeMiles",
+	} {
+		resp := suite.update(body)
+		suite.Equal(422, resp.StatusCode, body)
+		suite.Contains(resp.Body, `"field":"`+field+`"`, body)
+	}
+	suite.Empty(suite.turvo.RequestsTo("PUT", "/shipments/"+strconv.Itoa(suite.id)))
+}
+
+func (suite *UpdateLoadTestSuite) TestMergesStopsByIndex() {
+	resp := suite.update(`{"stops":[{},{"apptTime":"2025-08-03T17:00:00Z"}]}`)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+	resp = suite.update(`{"stops":[{"apptNote":"dock 4"}]}`)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+
+	var load model.LoadDetail
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &load))
+	suite.Require().Len(load.Stops, 2)
+	suite.Equal("dock 4", load.Stops[0].ApptNote)
+	suite.Equal("2025-08-03T12:00:00-05:00", load.Stops[1].ApptTime)
+}
+
+func (suite *UpdateLoadTestSuite) TestChecksTheLocationBusinessHours() {
+	// Saturday
+	resp := suite.update(`{"pickup":{"apptTime":"2025-08-02T15:00:00Z"}}`)
+	suite.Equal(422, resp.StatusCode, resp.Body)
+	suite.Contains(resp.Body, `outside businessHours \"Mon-Fri 07:00-16:00\"`)
+	suite.Empty(suite.turvo.RequestsTo("PUT", "/shipments/"+strconv.Itoa(suite.id)))
+
+	resp = suite.update(`{"pickup":{"apptTime":"2025-08-01T15:00:00Z"}}`)
+	suite.Equal(200, resp.StatusCode, resp.Body)
+}
+
+func (suite *UpdateLoadTestSuite) TestRejectsMalformedBody() {
+	resp := suite.update(`{"totalWeight":"heavy"}`)
+	suite.Equal(400, resp.StatusCode)
+	suite.Contains(resp.Body, `"code":"bad_request"`)
+	suite.Empty(suite.turvo.RequestsTo("GET", "/shipments/"+strconv.Itoa(suite.id)), "a bad body is refused before Turvo is called")
+
+	suite.id = 999999
+	resp = suite.update(`{"totalWeight":"heavy"}`)
+	suite.Equal(400, resp.StatusCode, "a bad body for an unknown load is still the caller's error")
+}
+
+func keys(m map[string]json.RawMessage) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func TestUpdateLoadTestSuite(t *testing.T) {
+	suite.Run(t, new(UpdateLoadTestSuite))
+}
@@ -1,11 +1,16 @@
 package view_loads_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"drumkit.com/interview/src/gateway"
 	"drumkit.com/interview/src/handler"
+	"drumkit.com/interview/src/model"
 	"drumkit.com/interview/src/service"
+	"drumkit.com/interview/src/test/faketurvo"
 	logger "drumkit.com/interview/src/utils"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/stretchr/testify/suite"
@@ -14,15 +19,30 @@ import (
 
 type ViewLoadsTestSuite struct {
 	suite.Suite
+	turvo   *faketurvo.Server
 	handler *handler.ViewLoadsHandler
 }
 
-func (suite *ViewLoadsTestSuite) SetupSuite() {
+func (suite *ViewLoadsTestSuite) SetupTest() {
 	logger.NewLogger()
+	suite.turvo = faketurvo.NewForTest(suite.T())
+	suite.seed()
 	gw := gateway.NewTurvoAPIGateway()
+	gw.Retry = gateway.RetryPolicy{MaxAttempts: 1}
 	svc := service.NewLoadService(gw)
 	suite.handler = &handler.ViewLoadsHandler{Service: svc}
-	// suite.T().Setenv("TOKEN", "test-token")
+}
+
+func (suite *ViewLoadsTestSuite) seed() {
+	for i := 0; i < 30; i++ {
+		suite.turvo.AddShipment(model.Shipment{
+			CustomID: fmt.Sprintf("SEED-%d", i),
+			Status:   model.Status{Code: model.StatusCode{Key: "2102", Value: "Covered"}},
+			CustomerOrder: []model.CustomerOrder{
+				{ID: 926781, Customer: model.Customer{ID: 973069, Name: "Bunker"}},
+			},
+		})
+	}
 }
 
 func (suite *ViewLoadsTestSuite) TestCreateLoadsHandlerLambda() {
@@ -32,14 +52,96 @@ func (suite *ViewLoadsTestSuite) TestCreateLoadsHandlerLambda() {
 			"pageSize": "20",
 		},
 	}
-	resp, err := suite.handler.ViewLoadsHandlerLambda(req)
+	resp, err := suite.handler.ViewLoadsHandlerLambda(context.Background(), req)
 	if err != nil {
 		logger.Logger.Error("Error in CreateLoadsHandlerLambda:", zap.Error(err))
 	}
 	suite.Equal(200, resp.StatusCode, "expected status code 200")
+
+	var page model.LoadPage
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &page))
+	suite.Len(page.Loads, 20)
+	suite.Equal("Bunker", page.Loads[0].CustomerOrder[0].Customer.Name)
+	suite.Equal(model.PageInfo{Start: 10, PageSize: 20, Count: 20, MoreAvailable: false}, page.Pagination)
 	logger.Logger.Info("CreateLoadsHandlerLambda executed successfully", zap.String("response", resp.Body))
 }
 
+func (suite *ViewLoadsTestSuite) TestCursorWalksEveryPage() {
+	seen := map[int]bool{}
+	params := map[string]string{"pageSize": "12"}
+	for pages := 1; ; pages++ {
+		resp, err := suite.handler.ViewLoadsHandlerLambda(context.Background(), events.APIGatewayProxyRequest{QueryStringParameters: params})
+		suite.Require().NoError(err)
+		suite.Require().Equal(200, resp.StatusCode, resp.Body)
+
+		var page model.LoadPage
+		suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &page))
+		for _, load := range page.Loads {
+			seen[load.ID] = true
+		}
+		if !page.Pagination.MoreAvailable {
+			suite.Equal(3, pages)
+			suite.Empty(page.Pagination.NextCursor)
+			break
+		}
+		params = map[string]string{"cursor": page.Pagination.NextCursor}
+	}
+	suite.Len(seen, 30)
+}
+
+func (suite *ViewLoadsTestSuite) TestFiltersAndSorts() {
+	for i, status := range []string{"Tendered", "Delivered", "Tendered"} {
+		suite.turvo.AddShipment(map[string]interface{}{
+			"customId":      fmt.Sprintf("FILTER-%d", i),
+			"status":        map[string]interface{}{"code": map[string]interface{}{"value": status, "key": map[string]string{"Tendered": "2101", "Delivered": "2107"}[status]}},
+			"customerOrder": []map[string]interface{}{{"id": 1, "customer": map[string]interface{}{"id": 555, "name": "Acme"}}},
+			"startDate":     map[string]interface{}{"date": fmt.Sprintf("2025-08-0%dT08:00:00Z", i+1)},
+		})
+	}
+
+	resp, err := suite.handler.ViewLoadsHandlerLambda(context.Background(), events.APIGatewayProxyRequest{
+		QueryStringParameters: map[string]string{"status": "Tendered", "customerId": "555", "sort": "-pickupDate"},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(200, resp.StatusCode, resp.Body)
+
+	var page model.LoadPage
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &page))
+	suite.Require().Len(page.Loads, 2)
+	suite.Equal("FILTER-2", page.Loads[0].CustomID)
+	suite.Equal("FILTER-0", page.Loads[1].CustomID)
+
+	reqs := suite.turvo.RequestsTo("GET", "/shipments/list")
+	suite.Equal("[2101]", reqs[len(reqs)-1].Query.Get("status[in]"))
+}
+
+func (suite *ViewLoadsTestSuite) TestRejectsBadPagination() {
+	for _, params := range []map[string]string{
+		{"start": "-1"},
+		{"pageSize": "ten"},
+		{"cursor": "not-a-cursor"},
+		{"status": "Lost"},
+		{"pickupFrom": "2025-08-10", "pickupTo": "2025-08-01"},
+	} {
+		resp, err := suite.handler.ViewLoadsHandlerLambda(context.Background(), events.APIGatewayProxyRequest{QueryStringParameters: params})
+		suite.Require().NoError(err)
+		suite.Equal(400, resp.StatusCode, "params %v", params)
+	}
+}
+
+func (suite *ViewLoadsTestSuite) TestUpstreamUnavailable() {
+	suite.turvo.Inject(faketurvo.Failure{Path: "/shipments/list", Status: 503, Body: `{"Status":"ERROR","details":{"errorMessage":"maintenance"}}`})
+
+	resp, err := suite.handler.ViewLoadsHandlerLambda(context.Background(), events.APIGatewayProxyRequest{})
+	suite.Require().NoError(err)
+	suite.Equal(503, resp.StatusCode)
+
+	var body handler.ErrorBody
+	suite.Require().NoError(json.Unmarshal([]byte(resp.Body), &body))
+	suite.Equal("upstream_unavailable", body.Error.Code)
+	suite.Equal("maintenance", body.Error.Upstream.Message())
+}
+
 func TestCreateLoadsTestSuite(t *testing.T) {
 	suite.Run(t, new(ViewLoadsTestSuite))
 }
@@ -0,0 +1,197 @@
+// Package validation checks request bodies before they reach the service and
+// reports every problem at once, each named by the JSON path of its field.
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"drumkit.com/interview/src/accessorial"
+	"drumkit.com/interview/src/model"
+	"drumkit.com/interview/src/rating"
+	"drumkit.com/interview/src/schedule"
+)
+
+// FieldError is a problem with one field, such as "pickup.apptTime".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors are the field errors found in a request, in the order the fields
+// were checked.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + " " + fe.Message
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+// checker collects field errors.
+type checker struct {
+	errs Errors
+}
+
+func (c *checker) add(field, message string) {
+	c.errs = append(c.errs, FieldError{Field: field, Message: message})
+}
+
+func (c *checker) check(field string, ok bool, message string) {
+	if !ok {
+		c.add(field, message)
+	}
+}
+
+func (c *checker) require(field, value string) {
+	c.check(field, strings.TrimSpace(value) != "", "is required")
+}
+
+func (c *checker) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+// CreateLoad checks a create load request. It returns Errors listing every
+// problem found, or nil.
+func CreateLoad(load model.CreateLoadRequest) error {
+	var c checker
+
+	c.check("customer.name", strings.TrimSpace(load.Customer.Name) != "" || strings.TrimSpace(load.Customer.ExternalTMSId) != "",
+		"is required when customer.externalTMSId is not set")
+	if status := strings.TrimSpace(load.Status); status == "" {
+		c.add("status", "is required")
+	} else if _, ok := model.StatusCodeForValue(status); !ok {
+		c.add("status", fmt.Sprintf("%q is not a known status", status))
+	}
+
+	c.stops(load)
+
+	for _, f := range []struct {
+		field string
+		value float64
+	}{
+		{"inPalletCount", float64(load.InPalletCount)},
+		{"outPalletCount", float64(load.OutPalletCount)},
+		{"numCommodities", float64(load.NumCommodities)},
+		{"totalWeight", load.TotalWeight},
+		{"billableWeight", load.BillableWeight},
+		{"routeMiles", load.RouteMiles},
+		{"rateData.customerNumHours", load.RateData.CustomerNumHours},
+		{"rateData.customerLhRateUsd", load.RateData.CustomerLhRateUsd},
+		{"rateData.fscPercent", load.RateData.FscPercent},
+		{"rateData.fscPerMile", load.RateData.FscPerMile},
+		{"rateData.carrierNumHours", load.RateData.CarrierNumHours},
+		{"rateData.carrierLhRateUsd", load.RateData.CarrierLhRateUsd},
+		{"rateData.carrierMaxRate", load.RateData.CarrierMaxRate},
+	} {
+		c.check(f.field, f.value >= 0, "cannot be negative")
+	}
+
+	c.rate(load)
+
+	spec := load.Specifications
+	c.check("specifications.maxTempFahrenheit", spec.MaxTempFahrenheit == 0 || spec.MaxTempFahrenheit >= spec.MinTempFahrenheit,
+		"is below minTempFahrenheit")
+
+	return c.err()
+}
+
+// stops checks the route: its stop types and order, and each stop's name,
+// times and business hours.
+func (c *checker) stops(load model.CreateLoadRequest) {
+	type routeStop struct {
+		field string
+		stop  model.CLStop
+	}
+	var route []routeStop
+	if len(load.Stops) > 0 {
+		for i, stop := range load.Stops {
+			route = append(route, routeStop{fmt.Sprintf("stops[%d]", i), stop})
+		}
+	} else {
+		route = []routeStop{{"pickup", load.Pickup.Stop()}, {"consignee", load.Consignee.Stop()}}
+	}
+	if len(route) < 2 {
+		c.add("stops", "need at least a pickup and a delivery")
+	}
+
+	var prev routeStop
+	var prevAppt time.Time
+	for i, rs := range route {
+		stop := rs.stop
+		stop.Type = strings.ToLower(strings.TrimSpace(stop.Type))
+		switch {
+		case stop.Type != model.StopTypePickup && stop.Type != model.StopTypeDelivery:
+			c.add(rs.field+".type", fmt.Sprintf("must be %q or %q", model.StopTypePickup, model.StopTypeDelivery))
+		case i == 0 && stop.Type != model.StopTypePickup:
+			c.add(rs.field+".type", "must be a pickup on the first stop")
+		case i == len(route)-1 && stop.Type != model.StopTypeDelivery:
+			c.add(rs.field+".type", "must be a delivery on the last stop")
+		}
+		c.require(rs.field+".name", stop.Name)
+
+		appt, ok := c.stopTimes(rs.field, stop)
+		if !ok {
+			continue
+		}
+		if !prevAppt.IsZero() && appt.Before(prevAppt) {
+			c.add(rs.field+".apptTime", "is before the appointment at "+prev.field)
+		}
+		prev, prevAppt = rs, appt
+	}
+}
+
+// stopTimes checks the times of the stop at field and returns its
+// appointment. ok is false when the appointment cannot be read.
+func (c *checker) stopTimes(field string, stop model.CLStop) (appt time.Time, ok bool) {
+	times, problems := schedule.Check(stop)
+	for _, p := range problems {
+		c.add(field+"."+p.Field, p.Message)
+	}
+	return times.Appt, !times.Appt.IsZero()
+}
+
+// rate checks the customer and carrier rates and accessorial charges.
+func (c *checker) rate(load model.CreateLoadRequest) {
+	rate := load.RateData
+	rateType, err := rating.ParseType(rate.CustomerRateType)
+	c.check("rateData.customerRateType", err == nil, "must be flat, perMile or hourly")
+	carrierType, err := rating.ParseType(rate.CarrierRateType)
+	c.check("rateData.carrierRateType", err == nil, "must be flat, perMile or hourly")
+	carrierRated := rate.CarrierLhRateUsd > 0
+
+	c.check("rateData.fscPerMile", rate.FscPercent == 0 || rate.FscPerMile == 0, "cannot be combined with fscPercent")
+	switch {
+	case (rateType == rating.TypePerMile || carrierRated && carrierType == rating.TypePerMile) && load.RouteMiles == 0:
+		c.add("routeMiles", "is required for a per-mile rate")
+	case rate.FscPerMile > 0 && load.RouteMiles == 0:
+		c.add("routeMiles", "is required for a per-mile fuel surcharge")
+	}
+	c.check("rateData.customerNumHours", rateType != rating.TypeHourly || rate.CustomerNumHours > 0, "is required for an hourly rate")
+	c.check("rateData.carrierNumHours", !carrierRated || carrierType != rating.TypeHourly || rate.CarrierNumHours > 0, "is required for an hourly rate")
+
+	names := make([]string, 0, len(rate.Accessorials))
+	for name := range rate.Accessorials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, charge := "rateData.accessorials."+name, rate.Accessorials[name]
+		a, ok := accessorial.Lookup(name)
+		switch {
+		case !ok:
+			c.add(field, "is not a known accessorial")
+		case charge < 0:
+			c.add(field, "cannot be negative")
+		case charge > 0 && !a.Requested(load.Specifications):
+			c.add(field, fmt.Sprintf("is charged but specifications.%s is not set", name))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package validation
+
+import (
+	"testing"
+
+	"drumkit.com/interview/src/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validLoad() model.CreateLoadRequest {
+	return model.CreateLoadRequest{
+		Status:    "Covered",
+		Customer:  model.CLCustomer{Name: "Bunker"},
+		Pickup:    model.CLPickup{Name: "A", State: "IL", ReadyTime: "2025-08-01T06:00", ApptTime: "2025-08-01T09:00"},
+		Consignee: model.CLConsignee{Name: "B", State: "TX", BusinessHours: "Mon-Fri 07:00-17:00", ApptTime: "2025-08-04T10:00", MustDeliver: "2025-08-04"},
+		RateData:  model.CLRateData{CustomerRateType: "flat", CustomerLhRateUsd: 2000, FscPercent: 10},
+	}
+}
+
+func TestCreateLoad_Valid(t *testing.T) {
+	assert.NoError(t, CreateLoad(validLoad()))
+
+	load := validLoad()
+	load.Customer = model.CLCustomer{ExternalTMSId: "973069"}
+	load.Stops = []model.CLStop{
+		{Type: "Pickup", Name: "A", ApptTime: "2025-08-01T08:00:00Z"},
+		{Type: "delivery", Name: "B", ApptTime: "2025-08-01T12:00:00Z"},
+		{Type: "delivery", Name: "C", ApptTime: "2025-08-02T12:00:00Z"},
+	}
+	assert.NoError(t, CreateLoad(load))
+}
+
+func TestCreateLoad_Rejects(t *testing.T) {
+	for name, tc := range map[string]struct {
+		change func(*model.CreateLoadRequest)
+		want   Errors
+	}{
+		"no customer": {
+			func(l *model.CreateLoadRequest) { l.Customer = model.CLCustomer{} },
+			Errors{{"customer.name", "is required when customer.externalTMSId is not set"}},
+		},
+		"no status": {
+			func(l *model.CreateLoadRequest) { l.Status = "" },
+			Errors{{"status", "is required"}},
+		},
+		"unknown status": {
+			func(l *model.CreateLoadRequest) { l.Status = "Lost" },
+			Errors{{"status", `"Lost" is not a known status`}},
+		},
+		"empty stop name": {
+			func(l *model.CreateLoadRequest) { l.Pickup.Name = " " },
+			Errors{{"pickup.name", "is required"}},
+		},
+		"delivery before pickup": {
+			func(l *model.CreateLoadRequest) {
+				l.Consignee.ApptTime, l.Consignee.MustDeliver = "2025-07-31T10:00", ""
+			},
+			Errors{{"consignee.apptTime", "is before the appointment at pickup"}},
+		},
+		"bad times": {
+			func(l *model.CreateLoadRequest) {
+				l.Pickup.ApptTime = "soon"
+				l.Consignee.MustDeliver = "2025-08-03"
+			},
+			Errors{{"pickup.apptTime", "is not a date and time"}, {"consignee.mustDeliver", "is before apptTime"}},
+		},
+		"ready after appointment": {
+			func(l *model.CreateLoadRequest) { l.Pickup.ReadyTime = "2025-08-01T10:00" },
+			Errors{{"pickup.readyTime", "is after apptTime"}},
+		},
+		"unknown timezone": {
+			func(l *model.CreateLoadRequest) { l.Pickup.Timezone = "Central" },
+			Errors{{"pickup.timezone", "is not a known IANA timezone"}},
+		},
+		"outside business hours": {
+			func(l *model.CreateLoadRequest) {
+				l.Consignee.ApptTime, l.Consignee.MustDeliver = "2025-08-04T18:00", ""
+			},
+			Errors{{"consignee.apptTime", `2025-08-04T18:00:00-05:00 is outside businessHours "Mon-Fri 07:00-17:00"`}},
+		},
+		"negative numbers": {
+			func(l *model.CreateLoadRequest) {
+				l.TotalWeight = -1
+				l.RateData.FscPercent = -5
+			},
+			Errors{{"totalWeight", "cannot be negative"}, {"rateData.fscPercent", "cannot be negative"}},
+		},
+		"rate": {
+			func(l *model.CreateLoadRequest) {
+				l.RateData.CustomerRateType = "perMile"
+				l.RateData.FscPerMile = 0.4
+			},
+			Errors{{"rateData.fscPerMile", "cannot be combined with fscPercent"}, {"routeMiles", "is required for a per-mile rate"}},
+		},
+		"hourly without hours": {
+			func(l *model.CreateLoadRequest) { l.RateData.CustomerRateType = "hourly" },
+			Errors{{"rateData.customerNumHours", "is required for an hourly rate"}},
+		},
+		"carrier rate": {
+			func(l *model.CreateLoadRequest) {
+				l.RateData.CarrierRateType, l.RateData.CarrierLhRateUsd = "hourly", 50
+			},
+			Errors{{"rateData.carrierNumHours", "is required for an hourly rate"}},
+		},
+		"unknown carrier rate type": {
+			func(l *model.CreateLoadRequest) { l.RateData.CarrierRateType = "per load" },
+			Errors{{"rateData.carrierRateType", "must be flat, perMile or hourly"}},
+		},
+		"unknown rate type": {
+			func(l *model.CreateLoadRequest) { l.RateData.CustomerRateType = "per pallet" },
+			Errors{{"rateData.customerRateType", "must be flat, perMile or hourly"}},
+		},
+		"accessorials": {
+			func(l *model.CreateLoadRequest) {
+				l.RateData.Accessorials = map[string]float64{"liftgateDelivery": 75, "detention": 50, "permits": 0}
+			},
+			Errors{
+				{"rateData.accessorials.detention", "is not a known accessorial"},
+				{"rateData.accessorials.liftgateDelivery", "is charged but specifications.liftgateDelivery is not set"},
+			},
+		},
+		"temperatures": {
+			func(l *model.CreateLoadRequest) {
+				l.Specifications.MinTempFahrenheit, l.Specifications.MaxTempFahrenheit = 40, 34
+			},
+			Errors{{"specifications.maxTempFahrenheit", "is below minTempFahrenheit"}},
+		},
+		"stop types": {
+			func(l *model.CreateLoadRequest) {
+				l.Stops = []model.CLStop{
+					{Type: "delivery", Name: "A", ApptTime: "2025-08-01T08:00"},
+					{Type: "crossdock", Name: "B", ApptTime: "2025-08-01T09:00"},
+					{Type: "pickup", Name: "C", ApptTime: "2025-08-01T10:00"},
+				}
+			},
+			Errors{
+				{"stops[0].type", "must be a pickup on the first stop"},
+				{"stops[1].type", `must be "pickup" or "delivery"`},
+				{"stops[2].type", "must be a delivery on the last stop"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			load := validLoad()
+			tc.change(&load)
+			err := CreateLoad(load)
+			var errs Errors
+			require.ErrorAs(t, err, &errs)
+			assert.Equal(t, tc.want, errs)
+		})
+	}
+}
+
+func TestErrors_Error(t *testing.T) {
+	err := Errors{{"pickup.name", "is required"}, {"totalWeight", "cannot be negative"}}
+	assert.EqualError(t, err, "invalid request: pickup.name is required; totalWeight cannot be negative")
+}
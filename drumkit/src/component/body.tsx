@@ -52,9 +52,10 @@ const Body: React.FC = () => {
                 
                 {/* Display the table with API data */}
                 <TableView 
-                    data={data || []} 
+                    data={data?.loads || []} 
                     loading={isLoading}
-                    totalRecords={1000} // You may want to get this from API response
+                    // Turvo does not report a total, so allow one more page while more are available
+                    totalRecords={data ? first + data.pagination.count + (data.pagination.moreAvailable ? rows : 0) : 0}
                     first={first}
                     rows={rows}
                     onPage={handlePageChange}
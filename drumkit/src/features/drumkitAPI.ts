@@ -49,6 +49,15 @@ export interface Load {
     state: string
     country: string
   }
+  // ordered multi-stop route; replaces pickup and consignee when present
+  stops?: Array<{
+    type: 'pickup' | 'delivery'
+    name: string
+    apptTime: string
+    city: string
+    state: string
+    country: string
+  }>
   status: string
   customer: {
     name: string
@@ -64,9 +73,21 @@ export interface Load {
 export interface ViewLoadsParams {
   start?: string
   pageSize?: string
+  cursor?: string
 }
 
-export interface ViewLoadsResponse extends Array<LoadData> {}
+export interface PageInfo {
+  start: number
+  pageSize: number
+  count: number
+  moreAvailable: boolean
+  nextCursor?: string
+}
+
+export interface ViewLoadsResponse {
+  loads: LoadData[]
+  pagination: PageInfo
+}
 
 export const drumkitAPI = createApi({
   reducerPath: 'drumkitAPI',
@@ -78,7 +99,7 @@ export const drumkitAPI = createApi({
   }),
   tagTypes: ['Load'],
   endpoints: (builder) => ({
-    viewLoads: builder.query({
+    viewLoads: builder.query<ViewLoadsResponse, ViewLoadsParams | undefined>({
       query: (params?: ViewLoadsParams) => {
         const searchParams = new URLSearchParams()
         if (params?.start) {
@@ -87,6 +108,9 @@ export const drumkitAPI = createApi({
         if (params?.pageSize) {
           searchParams.append('pageSize', params.pageSize)
         }
+        if (params?.cursor) {
+          searchParams.append('cursor', params.cursor)
+        }
         
         const queryString = searchParams.toString()
         